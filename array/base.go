@@ -2,10 +2,12 @@ package array
 
 import (
 	"encoding/binary"
+	"io"
 	"reflect"
 
 	"github.com/openacid/errors"
 	"github.com/openacid/low/bitmap"
+	"github.com/openacid/low/pbcmpl"
 	"github.com/openacid/slim/encode"
 )
 
@@ -167,3 +169,24 @@ func (a *Base) GetBytes(idx int32, eltsize int) ([]byte, bool) {
 	stIdx := int32(eltsize) * r
 	return a.Elts[stIdx : stIdx+int32(eltsize)], true
 }
+
+// WriteTo writes the array to w, with a header of version and size.
+// It implements io.WriterTo.
+// The body is encoded into a buffer before being written to w.
+//
+// Since 0.5.11
+func (a *Base) WriteTo(w io.Writer) (int64, error) {
+	n, err := pbcmpl.Marshal(w, a)
+	return n, errors.WithStack(err)
+}
+
+// ReadFrom reads an array written by WriteTo from r.
+// It reads exactly the bytes written by WriteTo, thus r can be a stream
+// with other data following the array.
+// It implements io.ReaderFrom.
+//
+// Since 0.5.11
+func (a *Base) ReadFrom(r io.Reader) (int64, error) {
+	n, _, err := pbcmpl.Unmarshal(r, a)
+	return n, err
+}
@@ -1,12 +1,14 @@
 package array_test
 
 import (
+	"bytes"
 	"reflect"
 	"testing"
 
 	proto "github.com/golang/protobuf/proto"
 	"github.com/kr/pretty"
 	"github.com/openacid/slim/array"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMarshalUnmarshal(t *testing.T) {
@@ -192,3 +194,56 @@ func TestMigrateToSignedCntAndOffsets(t *testing.T) {
 		t.Fatalf("expect -1 but: %v", b.Offsets)
 	}
 }
+
+func TestBase_WriteToReadFrom(t *testing.T) {
+
+	ta := require.New(t)
+
+	a1, err := array.NewU16([]int32{1, 5, 9, 203}, []uint16{12, 15, 19, 120})
+	ta.NoError(err)
+
+	a2, err := array.New([]int32{3, 4}, []uint32{7, 8})
+	ta.NoError(err)
+
+	// two arrays in one stream
+	w := &bytes.Buffer{}
+
+	n1, err := a1.WriteTo(w)
+	ta.NoError(err)
+	n2, err := a2.WriteTo(w)
+	ta.NoError(err)
+	ta.Equal(int64(w.Len()), n1+n2)
+
+	b1 := &array.U16{}
+	n, err := b1.ReadFrom(w)
+	ta.NoError(err)
+	ta.Equal(n1, n)
+
+	b2, err := array.NewEmpty(uint32(0))
+	ta.NoError(err)
+	n, err = b2.ReadFrom(w)
+	ta.NoError(err)
+	ta.Equal(n2, n)
+
+	ta.Equal(0, w.Len())
+
+	for _, i := range []int32{0, 1, 5, 9, 203, 204} {
+		v1, f1 := a1.Get(i)
+		v2, f2 := b1.Get(i)
+		ta.Equal(v1, v2)
+		ta.Equal(f1, f2)
+	}
+
+	v, found := b2.Get(4)
+	ta.True(found)
+	ta.Equal(uint32(8), v)
+
+	// truncated stream
+	w.Reset()
+	_, err = a1.WriteTo(w)
+	ta.NoError(err)
+	w.Truncate(w.Len() - 1)
+
+	_, err = b1.ReadFrom(w)
+	ta.Error(err)
+}
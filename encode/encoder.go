@@ -17,6 +17,12 @@ var (
 	// determined by its type.
 	// Such slice of interface.
 	ErrNotFixedSize = errors.New("element type is not fixed size")
+
+	// ErrUnknownEncoder indicates an Encoder type or an encoder name is not
+	// registered, or it is registered with a different version.
+	//
+	// Since 0.5.11
+	ErrUnknownEncoder = errors.New("unknown encoder")
 )
 
 // A Encoder converts one element between serialized byte stream
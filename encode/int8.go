@@ -23,3 +23,29 @@ func (c I8) GetSize(d interface{}) int {
 func (c I8) GetEncodedSize(b []byte) int {
 	return 1
 }
+
+// U8 converts uint8 to slice of 1 byte and back.
+//
+// Since 0.5.11
+type U8 struct{}
+
+// Encode converts uint8 to slice of 1 byte.
+func (c U8) Encode(d interface{}) []byte {
+	return []byte{d.(uint8)}
+}
+
+// Decode converts slice of 1 byte to uint8.
+// It returns number bytes consumed and an uint8.
+func (c U8) Decode(b []byte) (int, interface{}) {
+	return 1, b[0]
+}
+
+// GetSize returns the size in byte after encoding v.
+func (c U8) GetSize(d interface{}) int {
+	return 1
+}
+
+// GetEncodedSize returns 1.
+func (c U8) GetEncodedSize(b []byte) int {
+	return 1
+}
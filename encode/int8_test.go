@@ -39,3 +39,36 @@ func TestI8(t *testing.T) {
 		ta.Equal(c.wantsize, n)
 	}
 }
+
+func TestU8(t *testing.T) {
+
+	ta := require.New(t)
+
+	cases := []struct {
+		input    uint8
+		want     string
+		wantsize int
+	}{
+		{0, string([]byte{0}), 1},
+		{1, string([]byte{1}), 1},
+		{0x12, string([]byte{0x12}), 1},
+		{^uint8(0), string([]byte{0xff}), 1},
+	}
+
+	m := encode.U8{}
+
+	for _, c := range cases {
+		rst := m.Encode(c.input)
+		ta.Equal(c.want, string(rst))
+
+		n := m.GetSize(c.input)
+		ta.Equal(c.wantsize, n)
+
+		n = m.GetEncodedSize(rst)
+		ta.Equal(c.wantsize, n)
+
+		n, u8 := m.Decode(rst)
+		ta.Equal(c.input, u8)
+		ta.Equal(c.wantsize, n)
+	}
+}
@@ -0,0 +1,227 @@
+package encode
+
+import (
+	"math/bits"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/openacid/errors"
+)
+
+// ParamEncoder is an Encoder with parameters, such as Bytes, which has to be
+// recorded along with its name to rebuild the same Encoder.
+//
+// Since 0.5.11
+type ParamEncoder interface {
+	Encoder
+
+	// Param returns the parameters of this Encoder as a string.
+	Param() string
+
+	// WithParam returns an Encoder of the same type with parameters from
+	// Param().
+	WithParam(param string) (Encoder, error)
+}
+
+type registered struct {
+	name    string
+	version int
+	zero    Encoder
+}
+
+var registry = struct {
+	sync.RWMutex
+	byName map[string]*registered
+	byType map[reflect.Type]*registered
+}{
+	byName: map[string]*registered{},
+	byType: map[reflect.Type]*registered{},
+}
+
+func init() {
+	Register("bytes", 1, Bytes{})
+	Register("dummy", 1, Dummy{})
+	Register("string16", 1, String16{})
+	Register("int", 1, Int{})
+	Register("i8", 1, I8{})
+	Register("i16", 1, I16{})
+	Register("i32", 1, I32{})
+	Register("i64", 1, I64{})
+	Register("u8", 1, U8{})
+	Register("u16", 1, U16{})
+	Register("u32", 1, U32{})
+	Register("u64", 1, U64{})
+}
+
+// Register registers the type of Encoder e with a stable name and a version.
+// The name is written along with serialized data, thus a loader is able to
+// rebuild the Encoder by ByName().
+// The version should be increased if the encoded format changes.
+//
+// A name must not contain "/" or ":".
+// It panics if the name or the type of e is already registered.
+//
+// Since 0.5.11
+func Register(name string, version int, e Encoder) {
+
+	if name == "" || strings.ContainsAny(name, "/:") {
+		panic("invalid encoder name: " + strconv.Quote(name))
+	}
+
+	typ := reflect.TypeOf(e)
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	if _, ok := registry.byName[name]; ok {
+		panic("encoder name registered twice: " + name)
+	}
+	if _, ok := registry.byType[typ]; ok {
+		panic("encoder type registered twice: " + typ.String())
+	}
+
+	r := &registered{
+		name:    name,
+		version: version,
+		zero:    e,
+	}
+	registry.byName[name] = r
+	registry.byType[typ] = r
+}
+
+// NameOf returns the registered name of an Encoder, in form of
+// "<name>/<version>", or "<name>/<version>:<param>" for a ParamEncoder.
+// E.g. "i32/1" or "bytes/1:8".
+//
+// It returns ErrUnknownEncoder if the type of e is not registered.
+//
+// Since 0.5.11
+func NameOf(e Encoder) (string, error) {
+
+	typ := reflect.TypeOf(e)
+
+	registry.RLock()
+	r, ok := registry.byType[typ]
+	registry.RUnlock()
+
+	if !ok {
+		return "", errors.Wrapf(ErrUnknownEncoder, "type: %v", typ)
+	}
+
+	name := r.name + "/" + strconv.Itoa(r.version)
+
+	if pe, ok := e.(ParamEncoder); ok {
+		name += ":" + pe.Param()
+	}
+
+	return name, nil
+}
+
+// ByName creates an Encoder by a name returned by NameOf().
+//
+// It returns ErrUnknownEncoder if the name is not registered, or is registered
+// with a different version.
+//
+// Since 0.5.11
+func ByName(name string) (Encoder, error) {
+
+	spec, param := name, ""
+	hasParam := false
+	if i := strings.IndexByte(name, ':'); i >= 0 {
+		spec, param = name[:i], name[i+1:]
+		hasParam = true
+	}
+
+	i := strings.IndexByte(spec, '/')
+	if i < 0 {
+		return nil, errors.Wrapf(ErrUnknownEncoder, "no version: %q", name)
+	}
+
+	version, err := strconv.Atoi(spec[i+1:])
+	if err != nil {
+		return nil, errors.Wrapf(ErrUnknownEncoder, "invalid version: %q", name)
+	}
+
+	registry.RLock()
+	r, ok := registry.byName[spec[:i]]
+	registry.RUnlock()
+
+	if !ok {
+		return nil, errors.Wrapf(ErrUnknownEncoder, "%q", name)
+	}
+
+	if r.version != version {
+		return nil, errors.Wrapf(ErrUnknownEncoder, "%q, registered version: %d", name, r.version)
+	}
+
+	pe, isParam := r.zero.(ParamEncoder)
+	if isParam != hasParam {
+		return nil, errors.Wrapf(ErrUnknownEncoder, "%q, parameter mismatch", name)
+	}
+
+	if !isParam {
+		return r.zero, nil
+	}
+
+	e, err := pe.WithParam(param)
+	if err != nil {
+		return nil, errors.Wrapf(ErrUnknownEncoder, "%q: %v", name, err)
+	}
+	return e, nil
+}
+
+// Param returns c.Size.
+//
+// Since 0.5.11
+func (c Bytes) Param() string {
+	return strconv.Itoa(c.Size)
+}
+
+// WithParam returns a Bytes with Size parsed from param.
+//
+// Since 0.5.11
+func (c Bytes) WithParam(param string) (Encoder, error) {
+	size, err := strconv.Atoi(param)
+	if err != nil || size < 0 {
+		return nil, errors.Errorf("invalid size: %q", param)
+	}
+	return Bytes{Size: size}, nil
+}
+
+// Param returns c.Size.
+//
+// Since 0.5.11
+func (c Dummy) Param() string {
+	return strconv.Itoa(c.Size)
+}
+
+// WithParam returns a Dummy with Size parsed from param.
+//
+// Since 0.5.11
+func (c Dummy) WithParam(param string) (Encoder, error) {
+	size, err := strconv.Atoi(param)
+	if err != nil || size < 0 {
+		return nil, errors.Errorf("invalid size: %q", param)
+	}
+	return Dummy{Size: size}, nil
+}
+
+// Param returns the size of int in byte, which is different on 32-bit and
+// 64-bit platforms.
+//
+// Since 0.5.11
+func (c Int) Param() string {
+	return strconv.Itoa(bits.UintSize / 8)
+}
+
+// WithParam returns an Int if param is the size of int on this platform.
+//
+// Since 0.5.11
+func (c Int) WithParam(param string) (Encoder, error) {
+	if param != c.Param() {
+		return nil, errors.Errorf("int size: %s, int size on this platform: %s", param, c.Param())
+	}
+	return c, nil
+}
@@ -0,0 +1,80 @@
+package encode_test
+
+import (
+	"math/bits"
+	"strconv"
+	"testing"
+
+	"github.com/openacid/errors"
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+type myEncoder struct{ encode.I32 }
+
+func TestRegistry(t *testing.T) {
+
+	ta := require.New(t)
+
+	intSize := strconv.Itoa(bits.UintSize / 8)
+
+	cases := []struct {
+		e    encode.Encoder
+		want string
+	}{
+		{encode.I32{}, "i32/1"},
+		{encode.U64{}, "u64/1"},
+		{encode.String16{}, "string16/1"},
+		{encode.Bytes{Size: 8}, "bytes/1:8"},
+		{encode.Dummy{}, "dummy/1:0"},
+		{encode.Int{}, "int/1:" + intSize},
+	}
+
+	for i, c := range cases {
+
+		name, err := encode.NameOf(c.e)
+		ta.NoError(err, "%d-th: case: %+v", i+1, c)
+		ta.Equal(c.want, name, "%d-th: case: %+v", i+1, c)
+
+		e, err := encode.ByName(name)
+		ta.NoError(err, "%d-th: case: %+v", i+1, c)
+		ta.Equal(c.e, e, "%d-th: case: %+v", i+1, c)
+	}
+
+	_, err := encode.NameOf(myEncoder{})
+	ta.Equal(encode.ErrUnknownEncoder, errors.Cause(err))
+
+	te, err := encode.NewTypeEncoder(int32(0))
+	ta.NoError(err)
+	_, err = encode.NameOf(te)
+	ta.Equal(encode.ErrUnknownEncoder, errors.Cause(err))
+
+	for _, name := range []string{
+		"",
+		"i32",
+		"i32/x",
+		"i32/2",
+		"i32/1:8",
+		"bytes/1",
+		"bytes/1:x",
+		"int/1:3",
+		"my/1",
+	} {
+		_, err := encode.ByName(name)
+		ta.Equal(encode.ErrUnknownEncoder, errors.Cause(err), "name: %q", name)
+	}
+
+	encode.Register("my", 2, myEncoder{})
+
+	name, err := encode.NameOf(myEncoder{})
+	ta.NoError(err)
+	ta.Equal("my/2", name)
+
+	e, err := encode.ByName("my/2")
+	ta.NoError(err)
+	ta.Equal(myEncoder{}, e)
+
+	ta.Panics(func() { encode.Register("my", 1, te) })
+	ta.Panics(func() { encode.Register("my2", 1, myEncoder{}) })
+	ta.Panics(func() { encode.Register("a/b", 1, te) })
+}
@@ -28,6 +28,20 @@ type DataReader interface {
 	Read(offset int64, key string) (string, bool)
 }
 
+// ExactDataReader is an optional interface a DataReader implements to read a
+// record whose key is known to be present.
+//
+// If the SlimTrie of a SlimIndex is created with Opt.Complete, a key it finds
+// is always present.
+// In this case SlimIndex.Get() calls ReadExact() instead of Read(), to skip
+// checking the key of the record again.
+//
+// Since 0.5.11
+type ExactDataReader interface {
+	// ReadExact reads the value of the record at `offset`, which has `key`.
+	ReadExact(offset int64, key string) string
+}
+
 // OffsetIndexItem defines data types for a offset-based index, such as an index
 // of on-disk records.
 type OffsetIndexItem struct {
@@ -43,12 +57,18 @@ type OffsetIndexItem struct {
 type SlimIndex struct {
 	trie.SlimTrie
 	DataReader
+
+	// complete is true if SlimTrie is created with Opt.Complete.
+	// It is set by NewSlimIndex() so that Get() does not check options on
+	// every lookup.
+	complete bool
 }
 
 // NewSlimIndex creates SlimIndex instance.
 //
 // The keys in `index` must be in ascending order.
-func NewSlimIndex(index []OffsetIndexItem, dr DataReader) (*SlimIndex, error) {
+// `opts` is passed to create the SlimTrie, since 0.5.11.
+func NewSlimIndex(index []OffsetIndexItem, dr DataReader, opts ...trie.Opt) (*SlimIndex, error) {
 
 	l := len(index)
 	keys := make([]string, 0, l)
@@ -58,16 +78,24 @@ func NewSlimIndex(index []OffsetIndexItem, dr DataReader) (*SlimIndex, error) {
 		offsets = append(offsets, index[i].Offset)
 	}
 
-	st, err := trie.NewSlimTrie(encode.I64{}, keys, offsets)
+	st, err := trie.NewSlimTrie(encode.I64{}, keys, offsets, opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	return &SlimIndex{*st, dr}, nil
+	return &SlimIndex{
+		SlimTrie:   *st,
+		DataReader: dr,
+		complete:   *st.Options().Complete,
+	}, nil
 }
 
 // Get returns the value of `key` which is found by `SlimIndex.DataReader`, and
 // a bool value indicating if the `key` is found or not.
+//
+// Since 0.5.11, if the SlimIndex is created by NewSlimIndex() with
+// Opt.Complete and the DataReader is an ExactDataReader, the key of the record
+// is not checked again.
 func (si *SlimIndex) Get(key string) (string, bool) {
 	o, found := si.SlimTrie.Get(key)
 	if !found {
@@ -76,6 +104,10 @@ func (si *SlimIndex) Get(key string) (string, bool) {
 
 	offset := o.(int64)
 
+	if r, ok := si.DataReader.(ExactDataReader); ok && si.complete {
+		return r.ReadExact(offset, key), true
+	}
+
 	return si.DataReader.Read(offset, key)
 }
 
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/openacid/slim/index"
+	"github.com/openacid/slim/trie"
 )
 
 type testIndexData string
@@ -65,3 +66,70 @@ func TestSlimIndex(t *testing.T) {
 	}
 
 }
+
+// exactIndexData counts calls to Read and ReadExact.
+type exactIndexData struct {
+	testIndexData
+	reads, exactReads int
+}
+
+func (d *exactIndexData) Read(offset int64, key string) (string, bool) {
+	d.reads++
+	return d.testIndexData.Read(offset, key)
+}
+
+func (d *exactIndexData) ReadExact(offset int64, key string) string {
+	d.exactReads++
+	return strings.Split(string(d.testIndexData)[offset:], ",")[1]
+}
+
+func TestSlimIndex_exact(t *testing.T) {
+
+	keyOffsets := []index.OffsetIndexItem{
+		{Key: "Aaron", Offset: 0},
+		{Key: "Agatha", Offset: 8},
+		{Key: "Al", Offset: 17},
+		{Key: "Albert", Offset: 22},
+		{Key: "Alexander", Offset: 31},
+		{Key: "Alison", Offset: 43},
+	}
+
+	cases := []struct {
+		opt   trie.Opt
+		exact bool
+	}{
+		{trie.Opt{}, false},
+		{trie.Opt{InnerPrefix: trie.Bool(true)}, false},
+		{trie.Opt{Complete: trie.Bool(true)}, true},
+	}
+
+	for i, c := range cases {
+
+		data := &exactIndexData{
+			testIndexData: testIndexData("Aaron,1,Agatha,1,Al,2,Albert,3,Alexander,5,Alison,8"),
+		}
+
+		st, err := index.NewSlimIndex(keyOffsets, data, c.opt)
+		if err != nil {
+			t.Fatalf("expect no error but: %+v", err)
+		}
+
+		for _, k := range []string{"Al", "Alison", "Alexande"} {
+			st.Get(k)
+		}
+
+		v, found := st.Get("Albert")
+		if v != "3" || !found {
+			t.Fatalf("%d-th: Get Albert: %v %v", i+1, v, found)
+		}
+
+		// "Alexande" is not found by a complete SlimTrie.
+		ok := data.reads >= 3 && data.exactReads == 0
+		if c.exact {
+			ok = data.reads == 0 && data.exactReads == 3
+		}
+		if !ok {
+			t.Fatalf("%d-th: reads: %d, exact reads: %d", i+1, data.reads, data.exactReads)
+		}
+	}
+}
@@ -7,7 +7,31 @@ var (
 	// ErrKeyOutOfOrder means keys to create Trie are not ascendingly ordered.
 	ErrKeyOutOfOrder = errors.New("keys not ascending sorted")
 
+	// ErrDuplicateKey means there are duplicate keys to create Trie and
+	// DupError is specified.
+	ErrDuplicateKey = errors.New("duplicate key")
+
+	// ErrInvalidRange means a range to create RangeTrie is empty or overlaps
+	// with the previous one.
+	ErrInvalidRange = errors.New("invalid range")
+
 	// ErrIncompatible means it is trying to unmarshal data from an incompatible
 	// version.
 	ErrIncompatible = errors.New("incompatible with marshaled data")
+
+	// ErrMalformed means marshaled data is truncated or is not in the expected
+	// format.
+	ErrMalformed = errors.New("malformed marshaled data")
+
+	// ErrChecksumMismatch means the checksum of marshaled data does not match
+	// the one stored in its header, i.e., the data is corrupted.
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+
+	// ErrEncoderMismatch means the encoder of a SlimTrie is not the one the
+	// marshaled data is created with.
+	ErrEncoderMismatch = errors.New("encoder mismatch")
+
+	// ErrNotComplete means a SlimTrie is not created with Opt.Complete, thus
+	// keys can not be rebuilt from it.
+	ErrNotComplete = errors.New("not created with Opt.Complete")
 )
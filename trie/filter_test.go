@@ -0,0 +1,111 @@
+package trie
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/openacid/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilter(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	absentKeys := makeAbsentKeys(keys, 10000, 1, 10)
+
+	// empty ranges between adjacent absent keys
+	var emptyRanges [][2]string
+	for i := 0; i+1 < len(absentKeys); i++ {
+		from, to := absentKeys[i], absentKeys[i+1]
+		j := sort.SearchStrings(keys, from)
+		if j == len(keys) || keys[j] >= to {
+			emptyRanges = append(emptyRanges, [2]string{from, to})
+		}
+	}
+
+	// the false positive rates documented in Filter are measured with 50k
+	// absent keys, allow some more false positives here.
+	cases := []struct {
+		opt              Opt
+		pointFP, rangeFP float64
+	}{
+		{Opt{}, 0.20, 0.15},
+		{Opt{InnerPrefix: Bool(true)}, 0.20, 0.15},
+		{Opt{LeafPrefix: Bool(true)}, 0.001, 0.01},
+		{Opt{FingerprintBits: 8}, 0.002, 0.15},
+		{Opt{Complete: Bool(true)}, 0, 0},
+	}
+
+	for i, c := range cases {
+
+		f, err := NewFilter(keys, c.opt)
+		ta.NoError(err)
+
+		for _, k := range keys {
+			ta.True(f.MayContain(k), "%d-th: present key: %q", i+1, k)
+			ta.True(f.MayContainRange(k, k+"\x00"), "%d-th: present key: %q", i+1, k)
+		}
+
+		fp := 0
+		for _, k := range absentKeys {
+			if f.MayContain(k) {
+				fp++
+			}
+		}
+		rate := float64(fp) / float64(len(absentKeys))
+		ta.True(rate <= c.pointFP, "%d-th: MayContain false positive: %f", i+1, rate)
+
+		fp = 0
+		for _, r := range emptyRanges {
+			if f.MayContainRange(r[0], r[1]) {
+				fp++
+			}
+		}
+		rate = float64(fp) / float64(len(emptyRanges))
+		ta.True(rate <= c.rangeFP, "%d-th: MayContainRange false positive: %f", i+1, rate)
+
+		ta.True(f.MayContainRange("", ""), "%d-th", i+1)
+		ta.True(f.MayContainRange(keys[len(keys)-1], ""), "%d-th", i+1)
+		ta.False(f.MayContainRange(keys[2], keys[1]), "%d-th", i+1)
+	}
+}
+
+func TestFilter_empty(t *testing.T) {
+
+	ta := require.New(t)
+
+	f, err := NewFilter(nil)
+	ta.NoError(err)
+
+	ta.False(f.MayContain(""))
+	ta.False(f.MayContain("a"))
+	ta.False(f.MayContainRange("", ""))
+
+	_, err = NewFilter([]string{"b", "a"})
+	ta.Equal(ErrKeyOutOfOrder, errors.Cause(err))
+}
+
+func TestFilter_Marshal(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+
+	f1, err := NewFilter(keys, Opt{InnerPrefix: Bool(true), FingerprintBits: 8})
+	ta.NoError(err)
+
+	buf, err := f1.Marshal()
+	ta.NoError(err)
+
+	f2, err := NewFilter(nil)
+	ta.NoError(err)
+	ta.NoError(f2.Unmarshal(buf))
+
+	slimtrieEqual(f1.st, f2.st, t)
+
+	for _, k := range keys {
+		ta.True(f2.MayContain(k))
+	}
+}
@@ -0,0 +1,24 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package trie
+
+import (
+	"io"
+	"os"
+)
+
+// mmap reads the entire file into an 8-byte aligned buffer, on a platform
+// without mmap.
+func mmap(f *os.File, size int) ([]byte, error) {
+	buf := u64sAsBytes(make([]uint64, (size+7)/8))[:size]
+	_, err := io.ReadFull(f, buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+func munmap(b []byte) error {
+	return nil
+}
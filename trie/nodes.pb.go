@@ -285,10 +285,25 @@ type Nodes struct {
 	// Leaves stores serialized leaf values.
 	//
 	// Since 0.5.10
-	Leaves               *VLenArray `protobuf:"bytes,60,opt,name=Leaves,proto3" json:"Leaves,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
-	XXX_unrecognized     []byte     `json:"-"`
-	XXX_sizecache        int32      `json:"-"`
+	Leaves *VLenArray `protobuf:"bytes,60,opt,name=Leaves,proto3" json:"Leaves,omitempty"`
+	// FingerprintBits is the number of bits of the fingerprint of every leaf.
+	// 0 means no fingerprint is stored.
+	//
+	// Since 0.5.11
+	FingerprintBits int32 `protobuf:"varint,70,opt,name=FingerprintBits,proto3" json:"FingerprintBits,omitempty"`
+	// Fingerprints stores a FingerprintBits-bit hash of the key of every leaf.
+	// The fingerprint of the i-th leaf is at bit i*FingerprintBits.
+	//
+	// Since 0.5.11
+	Fingerprints *Bitmap `protobuf:"bytes,71,opt,name=Fingerprints,proto3" json:"Fingerprints,omitempty"`
+	// BuildOptions is the normalized options the SlimTrie is created with.
+	// It is nil in data created by an older version.
+	//
+	// Since 0.5.11
+	BuildOptions         *BuildOptions `protobuf:"bytes,80,opt,name=BuildOptions,proto3" json:"BuildOptions,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
 }
 
 func (m *Nodes) Reset()         { *m = Nodes{} }
@@ -399,40 +414,147 @@ func (m *Nodes) GetLeaves() *VLenArray {
 	return nil
 }
 
+func (m *Nodes) GetFingerprintBits() int32 {
+	if m != nil {
+		return m.FingerprintBits
+	}
+	return 0
+}
+
+func (m *Nodes) GetFingerprints() *Bitmap {
+	if m != nil {
+		return m.Fingerprints
+	}
+	return nil
+}
+
+func (m *Nodes) GetBuildOptions() *BuildOptions {
+	if m != nil {
+		return m.BuildOptions
+	}
+	return nil
+}
+
+// BuildOptions stores options to create a SlimTrie, except FingerprintBits,
+// which is stored in Nodes.
+//
+// Since 0.5.11
+type BuildOptions struct {
+	// DedupValue is Opt.DedupValue
+	//
+	// Since 0.5.11
+	DedupValue bool `protobuf:"varint,1,opt,name=DedupValue,proto3" json:"DedupValue,omitempty"`
+	// InnerPrefix is Opt.InnerPrefix
+	//
+	// Since 0.5.11
+	InnerPrefix bool `protobuf:"varint,2,opt,name=InnerPrefix,proto3" json:"InnerPrefix,omitempty"`
+	// LeafPrefix is Opt.LeafPrefix
+	//
+	// Since 0.5.11
+	LeafPrefix bool `protobuf:"varint,3,opt,name=LeafPrefix,proto3" json:"LeafPrefix,omitempty"`
+	// Complete is Opt.Complete
+	//
+	// Since 0.5.11
+	Complete             bool     `protobuf:"varint,4,opt,name=Complete,proto3" json:"Complete,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BuildOptions) Reset()         { *m = BuildOptions{} }
+func (m *BuildOptions) String() string { return proto.CompactTextString(m) }
+func (*BuildOptions) ProtoMessage()    {}
+func (*BuildOptions) Descriptor() ([]byte, []int) {
+	return fileDescriptor_nodes_c959d60ab49fe7f4, []int{3}
+}
+func (m *BuildOptions) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BuildOptions.Unmarshal(m, b)
+}
+func (m *BuildOptions) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BuildOptions.Marshal(b, m, deterministic)
+}
+func (dst *BuildOptions) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BuildOptions.Merge(dst, src)
+}
+func (m *BuildOptions) XXX_Size() int {
+	return xxx_messageInfo_BuildOptions.Size(m)
+}
+func (m *BuildOptions) XXX_DiscardUnknown() {
+	xxx_messageInfo_BuildOptions.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BuildOptions proto.InternalMessageInfo
+
+func (m *BuildOptions) GetDedupValue() bool {
+	if m != nil {
+		return m.DedupValue
+	}
+	return false
+}
+
+func (m *BuildOptions) GetInnerPrefix() bool {
+	if m != nil {
+		return m.InnerPrefix
+	}
+	return false
+}
+
+func (m *BuildOptions) GetLeafPrefix() bool {
+	if m != nil {
+		return m.LeafPrefix
+	}
+	return false
+}
+
+func (m *BuildOptions) GetComplete() bool {
+	if m != nil {
+		return m.Complete
+	}
+	return false
+}
+
 func init() {
 	proto.RegisterType((*Bitmap)(nil), "Bitmap")
 	proto.RegisterType((*VLenArray)(nil), "VLenArray")
 	proto.RegisterType((*Nodes)(nil), "Nodes")
+	proto.RegisterType((*BuildOptions)(nil), "BuildOptions")
 }
 
 func init() { proto.RegisterFile("nodes.proto", fileDescriptor_nodes_c959d60ab49fe7f4) }
 
 var fileDescriptor_nodes_c959d60ab49fe7f4 = []byte{
-	// 413 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x92, 0xc1, 0x8e, 0xd3, 0x30,
-	0x10, 0x86, 0x15, 0xa5, 0xc9, 0x6a, 0x27, 0xe9, 0xae, 0x64, 0x55, 0xe0, 0x03, 0xea, 0x9a, 0x1c,
-	0x16, 0x9f, 0x22, 0x04, 0x37, 0x04, 0x07, 0x82, 0x40, 0x5a, 0xa9, 0x29, 0x2b, 0x77, 0x05, 0x12,
-	0x07, 0xa4, 0xec, 0x66, 0x02, 0x56, 0x8b, 0x53, 0xd9, 0x06, 0xb5, 0xbc, 0x18, 0xef, 0xc0, 0x53,
-	0xa1, 0x38, 0x6d, 0x92, 0xb6, 0xdc, 0x3c, 0xdf, 0xff, 0x7b, 0xfc, 0xcf, 0xc8, 0x10, 0xa9, 0xba,
-	0x44, 0x93, 0xae, 0x75, 0x6d, 0xeb, 0xe4, 0x2b, 0x84, 0x99, 0xb4, 0x3f, 0x8a, 0x35, 0x99, 0x40,
-	0xf0, 0xb9, 0xd6, 0xa5, 0xa1, 0x13, 0xe6, 0xf3, 0x91, 0x68, 0x0b, 0xf2, 0x04, 0xce, 0x45, 0xa1,
-	0x96, 0x37, 0xaa, 0xc4, 0x0d, 0x9d, 0x32, 0x9f, 0x07, 0xa2, 0x07, 0x84, 0x41, 0xb4, 0xc0, 0x15,
-	0x3e, 0xd8, 0x56, 0xe7, 0x4e, 0x1f, 0xa2, 0xe4, 0x8f, 0x07, 0xe7, 0x9f, 0x66, 0xa8, 0xde, 0x6a,
-	0x5d, 0x6c, 0x49, 0x0c, 0xde, 0x9c, 0x02, 0xf3, 0x78, 0x20, 0xbc, 0x39, 0x79, 0x04, 0xe1, 0xfb,
-	0x95, 0x7d, 0xa7, 0x2c, 0x8d, 0x1c, 0xda, 0x55, 0xe4, 0x19, 0xc0, 0xad, 0x46, 0x83, 0xea, 0x01,
-	0xb3, 0x9c, 0xbe, 0x61, 0x1e, 0x8f, 0x5e, 0x9c, 0xa5, 0x6d, 0x4c, 0x31, 0x90, 0x9c, 0xb1, 0x36,
-	0xd2, 0xca, 0x5a, 0x65, 0x39, 0x9d, 0x1c, 0x1b, 0x3b, 0xa9, 0x99, 0xe2, 0x83, 0xdc, 0x60, 0xb9,
-	0x90, 0xbf, 0x91, 0x3e, 0x76, 0x8f, 0xf5, 0xa0, 0x99, 0x3c, 0xdb, 0x5a, 0x34, 0x74, 0xca, 0x3c,
-	0x1e, 0x8b, 0xb6, 0x48, 0xfe, 0xfa, 0x10, 0xcc, 0x9b, 0x4d, 0x35, 0x53, 0x66, 0xf2, 0xdb, 0x8d,
-	0x52, 0xa8, 0xfb, 0xb0, 0x43, 0x44, 0xae, 0xe1, 0x62, 0x5f, 0x7e, 0xac, 0x2a, 0x83, 0x96, 0xc6,
-	0xce, 0x74, 0x44, 0x09, 0x87, 0xcb, 0xc5, 0xf7, 0x5a, 0xdb, 0x5c, 0xaa, 0x9f, 0xc6, 0x09, 0x74,
-	0xec, 0x8c, 0xc7, 0xb8, 0x49, 0xec, 0x90, 0x4b, 0x7c, 0xd1, 0x26, 0xee, 0x40, 0xa7, 0xe6, 0x85,
-	0x59, 0xd2, 0x4b, 0xe6, 0xf1, 0x91, 0xe8, 0x41, 0xb3, 0x96, 0x26, 0xf8, 0xdd, 0x76, 0x8d, 0xff,
-	0x59, 0x4b, 0x2f, 0x91, 0x2b, 0x08, 0xdd, 0x6b, 0xed, 0xe4, 0x03, 0xd3, 0x0e, 0x93, 0xa7, 0x70,
-	0xe6, 0xda, 0x66, 0x39, 0xbd, 0x3a, 0x74, 0xec, 0x39, 0x99, 0x02, 0xb8, 0xe3, 0x5d, 0x71, 0xbf,
-	0x42, 0xca, 0x98, 0xcf, 0xc7, 0x62, 0x40, 0xc8, 0x73, 0x18, 0xbb, 0x66, 0xb7, 0x1a, 0x2b, 0xb9,
-	0x41, 0x43, 0xaf, 0x5d, 0x23, 0x48, 0xbb, 0x5f, 0x21, 0x0e, 0x0d, 0x24, 0x85, 0x78, 0x86, 0x45,
-	0xd5, 0x5d, 0x78, 0x75, 0x72, 0xe1, 0x40, 0x27, 0x09, 0x84, 0x33, 0x2c, 0x7e, 0xa1, 0xa1, 0xaf,
-	0x4f, 0x9c, 0x3b, 0x25, 0x0b, 0xbf, 0x8c, 0xac, 0x96, 0x78, 0x1f, 0xba, 0x5f, 0xff, 0xf2, 0x5f,
-	0x00, 0x00, 0x00, 0xff, 0xff, 0x97, 0x8c, 0x3a, 0x89, 0x04, 0x03, 0x00, 0x00,
+	// 516 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x94, 0xdd, 0x6a, 0xdb, 0x40,
+	0x10, 0x85, 0x51, 0x6d, 0x2b, 0xc9, 0x58, 0x4e, 0x60, 0x31, 0xed, 0x50, 0x8a, 0xa3, 0xfa, 0x22,
+	0x15, 0x14, 0x4c, 0x7f, 0xee, 0x4a, 0x7b, 0x51, 0xa5, 0x75, 0x09, 0xd8, 0x8e, 0x91, 0x43, 0x0a,
+	0xbd, 0x28, 0x28, 0xd1, 0x38, 0x5d, 0xa2, 0xac, 0xc4, 0xee, 0xba, 0xd8, 0x7d, 0x86, 0xbe, 0x4f,
+	0x5f, 0xa4, 0x0f, 0x54, 0x76, 0xe5, 0xe8, 0xc7, 0xe9, 0x9d, 0xe6, 0x3b, 0x67, 0x67, 0x67, 0x0e,
+	0x8b, 0xa0, 0x2b, 0xb2, 0x84, 0xd4, 0x28, 0x97, 0x99, 0xce, 0x86, 0xdf, 0xc1, 0x0d, 0xb9, 0xbe,
+	0x8b, 0x73, 0xd6, 0x87, 0xce, 0xd7, 0x4c, 0x26, 0x0a, 0xfb, 0x7e, 0x2b, 0x68, 0x47, 0x45, 0xc1,
+	0x9e, 0xc1, 0x41, 0x14, 0x8b, 0xdb, 0x33, 0x91, 0xd0, 0x1a, 0x07, 0x7e, 0x2b, 0xe8, 0x44, 0x15,
+	0x60, 0x3e, 0x74, 0x17, 0x94, 0xd2, 0xb5, 0x2e, 0xf4, 0xc0, 0xea, 0x75, 0x34, 0xfc, 0xe3, 0xc0,
+	0xc1, 0xe5, 0x84, 0xc4, 0x47, 0x29, 0xe3, 0x0d, 0xf3, 0xc0, 0x99, 0x21, 0xf8, 0x4e, 0xd0, 0x89,
+	0x9c, 0x19, 0x7b, 0x0c, 0xee, 0xe7, 0x54, 0x9f, 0x0a, 0x8d, 0x5d, 0x8b, 0xb6, 0x15, 0x7b, 0x01,
+	0x30, 0x97, 0xa4, 0x48, 0x5c, 0x53, 0x38, 0xc5, 0x0f, 0xbe, 0x13, 0x74, 0xdf, 0xec, 0x8d, 0x8a,
+	0x31, 0xa3, 0x9a, 0x64, 0x8d, 0x99, 0xe2, 0x9a, 0x67, 0x22, 0x9c, 0x62, 0x7f, 0xd7, 0x58, 0x4a,
+	0x66, 0x8b, 0x31, 0x5f, 0x53, 0xb2, 0xe0, 0xbf, 0x08, 0x9f, 0xd8, 0xcb, 0x2a, 0x60, 0x36, 0x0f,
+	0x37, 0x9a, 0x14, 0x0e, 0x7c, 0x27, 0xf0, 0xa2, 0xa2, 0x18, 0xfe, 0x6d, 0x43, 0x67, 0x66, 0x92,
+	0x32, 0x5b, 0x86, 0xfc, 0xe6, 0x4c, 0x08, 0x92, 0xd5, 0xb0, 0x75, 0xc4, 0x4e, 0xe0, 0xf0, 0xbe,
+	0x3c, 0x5f, 0x2e, 0x15, 0x69, 0xf4, 0xac, 0x69, 0x87, 0xb2, 0x00, 0x8e, 0x16, 0x3f, 0x32, 0xa9,
+	0xa7, 0x5c, 0xac, 0x94, 0x15, 0xb0, 0x67, 0x8d, 0xbb, 0xd8, 0x4c, 0x6c, 0x91, 0x9d, 0xf8, 0xb0,
+	0x98, 0xb8, 0x04, 0xa5, 0x3a, 0x8d, 0xd5, 0x2d, 0x1e, 0xf9, 0x4e, 0xd0, 0x8e, 0x2a, 0x60, 0x62,
+	0x31, 0x83, 0x5f, 0x6c, 0x72, 0xfa, 0x4f, 0x2c, 0x95, 0xc4, 0x8e, 0xc1, 0xb5, 0xb7, 0x15, 0x9b,
+	0xd7, 0x4c, 0x5b, 0xcc, 0x9e, 0xc3, 0x9e, 0x6d, 0x1b, 0x4e, 0xf1, 0xb8, 0xe9, 0xb8, 0xe7, 0x6c,
+	0x00, 0x60, 0x3f, 0x2f, 0xe2, 0xab, 0x94, 0xd0, 0xf7, 0x5b, 0x41, 0x2f, 0xaa, 0x11, 0xf6, 0x0a,
+	0x7a, 0xb6, 0xd9, 0x5c, 0xd2, 0x92, 0xaf, 0x49, 0xe1, 0x89, 0x6d, 0x04, 0xa3, 0xf2, 0x55, 0x44,
+	0x4d, 0x03, 0x1b, 0x81, 0x37, 0xa1, 0x78, 0x59, 0x1e, 0x78, 0xf7, 0xe0, 0x40, 0x43, 0x67, 0x43,
+	0x70, 0x27, 0x14, 0xff, 0x24, 0x85, 0xef, 0x1f, 0x38, 0xb7, 0x8a, 0x09, 0x7e, 0xcc, 0xc5, 0x0d,
+	0xc9, 0x5c, 0x72, 0xa1, 0x43, 0xae, 0x15, 0x8e, 0x8b, 0xe0, 0x77, 0x30, 0x7b, 0x09, 0x5e, 0x0d,
+	0x29, 0xfc, 0xd2, 0xdc, 0xbb, 0x21, 0xb2, 0xd7, 0xe0, 0x85, 0x2b, 0x9e, 0x26, 0xe7, 0xb9, 0x79,
+	0x68, 0x0a, 0xe7, 0xd6, 0xdc, 0x1b, 0xd5, 0x61, 0xd4, 0xb0, 0x0c, 0x7f, 0x3b, 0xcd, 0x33, 0x26,
+	0xc0, 0x4f, 0x94, 0xac, 0xf2, 0xcb, 0x38, 0x5d, 0x11, 0x3a, 0xbe, 0x13, 0xec, 0x47, 0x35, 0x62,
+	0x5e, 0x5f, 0x2d, 0x1f, 0x7c, 0x64, 0x0d, 0x75, 0x64, 0x3a, 0x54, 0x81, 0x60, 0xab, 0xe8, 0x50,
+	0x11, 0xf6, 0x14, 0xf6, 0x4f, 0xb3, 0xbb, 0x3c, 0x25, 0x4d, 0xd8, 0xb6, 0x6a, 0x59, 0x87, 0xee,
+	0xb7, 0xb6, 0x96, 0x9c, 0xae, 0x5c, 0xfb, 0x3b, 0x78, 0xfb, 0x6f, 0x00, 0x7b, 0x52, 0x74, 0x29,
+	0x1d, 0x04, 0x00, 0x00,
 }
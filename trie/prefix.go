@@ -1,7 +1,6 @@
 package trie
 
 import (
-	"bytes"
 	"math/bits"
 
 	"github.com/openacid/low/bitmap"
@@ -70,7 +69,7 @@ func prefixCompare(key string, cPref []byte) int {
 
 	if cPref[0]&1 == 0 || kl < pl {
 		if pl > 8 {
-			return bytes.Compare([]byte(key[:kl]), pref)
+			return cmpStrBytes(key[:kl], pref)
 		} else {
 			var i int32
 			for i = 0; i < kl; i++ {
@@ -91,12 +90,8 @@ func prefixCompare(key string, cPref []byte) int {
 
 	pl--
 
-	// sub := key[:pl]
-	// sbytes := []byte(key[:pl])
-
 	if pl > 8 {
-		// rst := bytes.Compare(sbytes, pref[:pl])
-		rst := bytes.Compare([]byte(key[:pl]), pref[:pl])
+		rst := cmpStrBytes(key[:pl], pref[:pl])
 		if rst != 0 {
 			return rst
 		}
@@ -128,3 +123,18 @@ func prefixCompare(key string, cPref []byte) int {
 	}
 	return 0
 }
+
+// cmpStrBytes compares a string with a byte slice, like bytes.Compare does.
+//
+// Converting []byte to string in a comparison expression does not copy the
+// bytes, thus there is no allocation, while converting a string to []byte
+// does if it is longer than 32 bytes.
+func cmpStrBytes(s string, b []byte) int {
+	if s == string(b) {
+		return 0
+	}
+	if s < string(b) {
+		return -1
+	}
+	return 1
+}
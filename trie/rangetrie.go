@@ -0,0 +1,183 @@
+package trie
+
+import (
+	"github.com/openacid/errors"
+	"github.com/openacid/slim/encode"
+)
+
+// Range is a key range [Start, End) and the value it maps to.
+// An empty End means there is no upper bound.
+//
+// Since 0.5.11
+type Range struct {
+	Start string
+	End   string
+	Value interface{}
+}
+
+// RangeTrie maps non-overlapping key ranges to values.
+// Unlike SlimTrie.RangeGet(), it tells a key in a range apart from a key in a
+// gap between ranges.
+//
+// It is a SlimTrie of range bounds: the start of a range maps to the value of
+// the range, the end of a range maps to a gap, unless it is the start of the
+// next range.
+//
+// Since 0.5.11
+type RangeTrie struct {
+	st *SlimTrie
+}
+
+// rangeGap is the leaf value of a range end that starts a gap.
+type rangeGap struct{}
+
+// rangeLeaf is a leaf value in RangeTrie.
+type rangeLeaf struct {
+	gap   bool
+	value interface{}
+}
+
+// rangeEncoder encodes a rangeLeaf as a flag byte followed by the value
+// encoded with the user encoder.
+// A gap is a single 0 byte.
+type rangeEncoder struct {
+	e encode.Encoder
+}
+
+func (c rangeEncoder) Encode(d interface{}) []byte {
+	l := d.(rangeLeaf)
+	if l.gap {
+		return []byte{0}
+	}
+	if c.e == nil {
+		return []byte{1}
+	}
+	return append([]byte{1}, c.e.Encode(l.value)...)
+}
+
+func (c rangeEncoder) Decode(b []byte) (int, interface{}) {
+	if b[0] == 0 {
+		return 1, rangeGap{}
+	}
+	if c.e == nil {
+		return 1, nil
+	}
+	n, v := c.e.Decode(b[1:])
+	return n + 1, v
+}
+
+func (c rangeEncoder) GetSize(d interface{}) int {
+	return len(c.Encode(d))
+}
+
+func (c rangeEncoder) GetEncodedSize(b []byte) int {
+	if b[0] == 0 || c.e == nil {
+		return 1
+	}
+	return 1 + c.e.GetEncodedSize(b[1:])
+}
+
+// NewRangeTrie creates a RangeTrie.
+// Ranges must be sorted by Start and must not overlap.
+// Argument e encodes range values. If e is nil, values are not stored and
+// Get() only tells if a key is in a range.
+//
+// With Opt.Complete the result of Get() is exact.
+// Otherwise only range bounds are located correctly, a key that is not a bound
+// may be reported in a wrong range or gap, just like SlimTrie.RangeGet().
+//
+// Since 0.5.11
+func NewRangeTrie(e encode.Encoder, ranges []Range, opts ...Opt) (*RangeTrie, error) {
+
+	keys := make([]string, 0, len(ranges)*2)
+	leaves := make([]rangeLeaf, 0, len(ranges)*2)
+
+	for i, r := range ranges {
+
+		if r.End != "" && r.End <= r.Start {
+			return nil, errors.Wrapf(ErrInvalidRange,
+				"ranges[%d]: start %q >= end %q", i, r.Start, r.End)
+		}
+
+		if i > 0 {
+			prev := ranges[i-1]
+
+			if prev.Start >= r.Start {
+				return nil, errors.Wrapf(ErrKeyOutOfOrder,
+					"ranges[%d].Start >= ranges[%d].Start %s %s", i-1, i, prev.Start, r.Start)
+			}
+
+			if prev.End == "" || prev.End > r.Start {
+				return nil, errors.Wrapf(ErrInvalidRange,
+					"ranges[%d] overlaps ranges[%d]", i-1, i)
+			}
+
+			if prev.End < r.Start {
+				keys = append(keys, prev.End)
+				leaves = append(leaves, rangeLeaf{gap: true})
+			}
+		}
+
+		keys = append(keys, r.Start)
+		leaves = append(leaves, rangeLeaf{value: r.Value})
+	}
+
+	if len(ranges) > 0 {
+		last := ranges[len(ranges)-1]
+		if last.End != "" {
+			keys = append(keys, last.End)
+			leaves = append(leaves, rangeLeaf{gap: true})
+		}
+	}
+
+	st, err := NewSlimTrie(rangeEncoder{e}, keys, leaves, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RangeTrie{st: st}, nil
+}
+
+// Get returns the value of the range that contains key.
+// It returns false if key is not in any range.
+//
+// Since 0.5.11
+func (rt *RangeTrie) Get(key string) (interface{}, bool) {
+
+	st := rt.st
+	if st.nodes.NodeTypeBM == nil {
+		return nil, false
+	}
+
+	lID, eqID, _ := st.searchID(key)
+	if eqID == -1 {
+		eqID = lID
+	}
+
+	if eqID == -1 {
+		return nil, false
+	}
+
+	v := st.getLeaf(eqID)
+	if _, ok := v.(rangeGap); ok {
+		return nil, false
+	}
+
+	return v, true
+}
+
+// Marshal serializes a RangeTrie.
+//
+// Since 0.5.11
+func (rt *RangeTrie) Marshal() ([]byte, error) {
+	return rt.st.Marshal()
+}
+
+// Unmarshal a RangeTrie from a byte slice.
+// The RangeTrie must be created with the same encoder as the one marshaled,
+// e.g., with NewRangeTrie(e, nil).
+//
+// Since 0.5.11
+func (rt *RangeTrie) Unmarshal(buf []byte) error {
+	return rt.st.Unmarshal(buf)
+}
@@ -0,0 +1,169 @@
+package trie
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/openacid/errors"
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRangeTrie(t *testing.T) {
+
+	ta := require.New(t)
+
+	ranges := []Range{
+		{"abc", "abd", int32(1)},
+		{"b", "bc", int32(2)},
+		{"bc", "c", int32(3)},
+		{"d", "", int32(4)},
+	}
+
+	cases := []struct {
+		key   string
+		want  interface{}
+		found bool
+	}{
+		{"", nil, false},
+		{"ab", nil, false},
+		{"abc", int32(1), true},
+		{"abd", nil, false},
+		{"b", int32(2), true},
+		{"bc", int32(3), true},
+		{"c", nil, false},
+		{"d", int32(4), true},
+	}
+
+	for _, opt := range []Opt{{}, {InnerPrefix: Bool(true)}, {Complete: Bool(true)}} {
+
+		rt, err := NewRangeTrie(encode.I32{}, ranges, opt)
+		ta.NoError(err)
+
+		for i, c := range cases {
+			v, found := rt.Get(c.key)
+			ta.Equal(c.want, v, "%d-th: opt: %+v, case: %+v", i+1, opt, c)
+			ta.Equal(c.found, found, "%d-th: opt: %+v, case: %+v", i+1, opt, c)
+		}
+	}
+
+	// keys between bounds
+
+	rt, err := NewRangeTrie(encode.I32{}, ranges, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	cases = []struct {
+		key   string
+		want  interface{}
+		found bool
+	}{
+		{"abcz", int32(1), true},
+		{"abda", nil, false},
+		{"bb", int32(2), true},
+		{"bz", int32(3), true},
+		{"cz", nil, false},
+		{"zzz", int32(4), true},
+	}
+
+	for i, c := range cases {
+		v, found := rt.Get(c.key)
+		ta.Equal(c.want, v, "%d-th: case: %+v", i+1, c)
+		ta.Equal(c.found, found, "%d-th: case: %+v", i+1, c)
+	}
+}
+
+func TestRangeTrie_random(t *testing.T) {
+
+	ta := require.New(t)
+
+	bounds := randVStrings(2000, 1, 10)
+	sort.Strings(bounds)
+
+	// make a range of every 2 adjacent bounds, and randomly skip some to make
+	// gaps.
+	ranges := []Range{}
+	for i := 0; i < len(bounds)-1; i++ {
+		if rand.Intn(3) == 0 {
+			continue
+		}
+		ranges = append(ranges, Range{bounds[i], bounds[i+1], int32(i)})
+	}
+
+	lookup := func(key string) (interface{}, bool) {
+		for _, r := range ranges {
+			if r.Start <= key && key < r.End {
+				return r.Value, true
+			}
+		}
+		return nil, false
+	}
+
+	queries := append(randVStrings(2000, 0, 12), bounds...)
+
+	for _, e := range []encode.Encoder{encode.I32{}, nil} {
+
+		rt, err := NewRangeTrie(e, ranges, Opt{Complete: Bool(true)})
+		ta.NoError(err)
+
+		for _, key := range queries {
+			want, wantFound := lookup(key)
+			if e == nil {
+				want = nil
+			}
+
+			v, found := rt.Get(key)
+			ta.Equal(wantFound, found, "key: %q", key)
+			ta.Equal(want, v, "key: %q", key)
+		}
+	}
+}
+
+func TestRangeTrie_error(t *testing.T) {
+
+	ta := require.New(t)
+
+	cases := []struct {
+		ranges []Range
+		want   error
+	}{
+		{[]Range{{"b", "a", 1}}, ErrInvalidRange},
+		{[]Range{{"a", "a", 1}}, ErrInvalidRange},
+		{[]Range{{"a", "c", 1}, {"b", "d", 2}}, ErrInvalidRange},
+		{[]Range{{"a", "", 1}, {"b", "d", 2}}, ErrInvalidRange},
+		{[]Range{{"b", "c", 1}, {"a", "b", 2}}, ErrKeyOutOfOrder},
+		{[]Range{{"a", "b", 1}, {"a", "c", 2}}, ErrKeyOutOfOrder},
+	}
+
+	for i, c := range cases {
+		_, err := NewRangeTrie(encode.Int{}, c.ranges)
+		ta.Equal(c.want, errors.Cause(err), "%d-th: case: %+v", i+1, c)
+	}
+}
+
+func TestRangeTrie_MarshalUnmarshal(t *testing.T) {
+
+	ta := require.New(t)
+
+	ranges := []Range{
+		{"a", "b", "x"},
+		{"c", "d", "yy"},
+	}
+
+	rt1, err := NewRangeTrie(encode.String16{}, ranges, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	buf, err := rt1.Marshal()
+	ta.NoError(err)
+
+	rt2, err := NewRangeTrie(encode.String16{}, nil)
+	ta.NoError(err)
+	ta.NoError(rt2.Unmarshal(buf))
+
+	for _, k := range []string{"a", "az", "b", "c", "cz", "d"} {
+		v1, f1 := rt1.Get(k)
+		v2, f2 := rt2.Get(k)
+		ta.Equal(v1, v2, "key: %q", k)
+		ta.Equal(f1, f2, "key: %q", k)
+	}
+}
@@ -2,6 +2,7 @@ package trie
 
 import (
 	"math/bits"
+	"sort"
 
 	"github.com/openacid/low/bitmap"
 )
@@ -110,3 +111,45 @@ func (bm *Bitmap) select32(i int32) (int32, int32) {
 	}
 	return a, l << 6
 }
+
+// selectR64 returns the position of the i-th "1" in a bitmap, with the help of
+// a rank64 index.
+// It does a binary search on the rank index thus is slower than select32.
+// The i-th "1" must exist.
+func selectR64(words []uint64, rindex []int32, i int32) int32 {
+
+	wordI := int32(sort.Search(len(rindex), func(j int) bool {
+		return rindex[j] > i
+	})) - 1
+
+	return wordI<<6 + selectU64(words[wordI], i-rindex[wordI])
+}
+
+// selectR128 returns the position of the i-th "1" in a bitmap, with the help
+// of a rank128 index.
+// The i-th "1" must exist.
+func selectR128(words []uint64, rindex []int32, i int32) int32 {
+
+	j := int32(sort.Search(len(rindex), func(j int) bool {
+		return rindex[j] > i
+	})) - 1
+
+	wordI := j << 1
+	i -= rindex[j]
+
+	ones := int32(bits.OnesCount64(words[wordI]))
+	if i >= ones {
+		i -= ones
+		wordI++
+	}
+
+	return wordI<<6 + selectU64(words[wordI], i)
+}
+
+// selectU64 returns the position of the i-th "1" in a uint64.
+func selectU64(w uint64, i int32) int32 {
+	for ; i > 0; i-- {
+		w &= w - 1
+	}
+	return int32(bits.TrailingZeros64(w))
+}
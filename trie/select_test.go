@@ -76,6 +76,40 @@ func TestSelect32(t *testing.T) {
 	}
 }
 
+func TestSelectR64R128(t *testing.T) {
+
+	ta := require.New(t)
+
+	cases := []struct {
+		input []uint64
+	}{
+		{[]uint64{1}},
+		{[]uint64{2}},
+		{[]uint64{3}},
+		{[]uint64{4, 0}},
+		{[]uint64{0xf, 0xf}},
+		{[]uint64{0xf, 0, 0xf}},
+		{[]uint64{0, 0, 0, 0xf}},
+		{[]uint64{0xfffffffffffffff0}},
+		{[]uint64{0xffffffffffffffff, 0, 0x8000000000000000}},
+		{[]uint64{0xffffffff, 0xffffffff, 1}},
+		{[]uint64{0x6668}}, // 000101100110011
+	}
+
+	for _, c := range cases {
+
+		r64 := bitmap.IndexRank64(c.input)
+		r128 := bitmap.IndexRank128(c.input)
+
+		all := bitmap.ToArray(c.input)
+
+		for j := 0; j < len(all); j++ {
+			ta.Equal(all[j], selectR64(c.input, r64, int32(j)), "selectR64: %d, case: %+v", j, c)
+			ta.Equal(all[j], selectR128(c.input, r128, int32(j)), "selectR128: %d, case: %+v", j, c)
+		}
+	}
+}
+
 func BenchmarkSelect32(b *testing.B) {
 	words := []uint64{0xffffffff, 0xffffffff, 1}
 	var s int32
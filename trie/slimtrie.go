@@ -83,6 +83,12 @@ const (
 type SlimTrie struct {
 	nodes   *Nodes
 	encoder encode.Encoder
+
+	// mapped is the memory mapped file a SlimTrie is loaded from by Open().
+	mapped []byte
+
+	// validateOnLoad is Opt.ValidateOnLoad.
+	validateOnLoad bool
 }
 
 // Opt specifies options for creating a SlimTrie.
@@ -112,9 +118,29 @@ type Opt struct {
 	// by only recording the index of a.
 	// Because we know that a<b<c, and offsetOf(c) - offsetOf(a) < 4KB
 	//
+	// To store the exact offset of a, see ValueGroup.
+	//
 	// Since 0.5.10
 	DedupValue *bool
 
+	// ValueGroup maps a value to a group key, with which DedupValue decides
+	// whether two adjacent values are the same, instead of comparing encoded
+	// values.
+	// A key is removed if the group key of its value equals the one of the
+	// previous key, and SlimTrie stores the value of the first key in a group.
+	// Group keys must be comparable with "==".
+	//
+	// E.g., group disk offsets into 4KB blocks, while the exact offset of the
+	// first key in every block is stored:
+	//
+	//	func(v interface{}) interface{} { return v.(int64) &^ (4096 - 1) }
+	//
+	// It is ignored if DedupValue is false or there are no values.
+	// Default nil.
+	//
+	// Since 0.5.11
+	ValueGroup func(v interface{}) interface{}
+
 	// InnerPrefix tells SlimTrie to store text on a trie branch to inner
 	// node(not to leaf node), instead of storing only branch length.
 	// With this option SlimTrie costs more space but reduces false positive
@@ -143,6 +169,31 @@ type Opt struct {
 	//
 	// Since 0.5.10
 	Complete *bool
+
+	// FingerprintBits tells SlimTrie to store a hash of FingerprintBits bits
+	// of every key.
+	// GetID() compares it with the hash of the key to look up, thus an absent
+	// key is found by mistake with a probability of about 2^-FingerprintBits,
+	// at the cost of FingerprintBits bits per key.
+	//
+	// Like with Complete, a key removed by DedupValue is not found by GetID().
+	//
+	// It is at most 64 and is ignored if Complete is set, which has no false
+	// positive.
+	// Default 0.
+	//
+	// Since 0.5.11
+	FingerprintBits int32
+
+	// ValidateOnLoad tells SlimTrie to call Validate() after it is loaded by
+	// Unmarshal(), ReadFrom() or UnmarshalNoCopy(), and to return the error
+	// if the loaded data is malformed.
+	// It is a loading option and does not affect how a SlimTrie is created.
+	//
+	// Default false.
+	//
+	// Since 0.5.11
+	ValidateOnLoad *bool
 }
 
 func Bool(v bool) *bool {
@@ -159,9 +210,19 @@ func normalizeOpt(o *Opt) *Opt {
 	if o.LeafPrefix == nil {
 		o.LeafPrefix = Bool(false)
 	}
+	if o.ValidateOnLoad == nil {
+		o.ValidateOnLoad = Bool(false)
+	}
 	if o.Complete != nil && *o.Complete == true {
 		o.InnerPrefix = Bool(true)
 		o.LeafPrefix = Bool(true)
+		o.FingerprintBits = 0
+	}
+	if o.FingerprintBits < 0 {
+		o.FingerprintBits = 0
+	}
+	if o.FingerprintBits > 64 {
+		o.FingerprintBits = 64
 	}
 	return o
 }
@@ -179,6 +240,7 @@ func (st *SlimTrie) compatibleVersions() []string {
 		"==1.0.0", // before 0.5.8 it is "1.0.0" for historical reason.
 		"==0.5.8",
 		"==0.5.9",
+		"==0.5.10",
 		"==" + slimtrieVersion,
 	}
 }
@@ -202,7 +264,13 @@ func NewSlimTrie(e encode.Encoder, keys []string, values interface{}, opts ...Op
 
 	normalizeOpt(&opt)
 
-	return newSlimTrie(e, keys, values, &opt)
+	st, err := newSlimTrie(e, keys, values, &opt)
+	if err != nil {
+		return nil, err
+	}
+
+	st.validateOnLoad = *opt.ValidateOnLoad
+	return st, nil
 }
 
 // func (st *SlimTrie) GetStat() map[string]float64 {
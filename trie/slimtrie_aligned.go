@@ -0,0 +1,424 @@
+package trie
+
+import (
+	"encoding/binary"
+	"reflect"
+	"unsafe"
+
+	"github.com/openacid/errors"
+)
+
+// The aligned format stores Nodes in a fixed layout, so that it can be loaded
+// without parsing or copying: slices in Nodes point directly into the buffer.
+//
+// Every field is stored in the order defined by alignedWriter.nodes():
+// an integer is stored in 8 bytes, a slice is stored as its length in 8 bytes
+// followed by its elements, padded to 8 bytes.
+// All integers are little-endian.
+//
+//	magic: "slimtrie"
+//	format version
+//	encoder name, empty if there is no encoder or it is not registered
+//	fields of Nodes ...
+
+// alignedMagic starts a buffer in aligned format.
+const alignedMagic = "slimtrie"
+
+// alignedVersion is the version of aligned format.
+const alignedVersion = 1
+
+// isLittleEndian is true if the native byte order is little-endian, in which
+// case integer slices can be viewed in place.
+var isLittleEndian = func() bool {
+	x := uint16(1)
+	return *(*byte)(unsafe.Pointer(&x)) == 1
+}()
+
+// MarshalAligned serializes SlimTrie in aligned format, which can be loaded
+// without copy by UnmarshalNoCopy() or Open().
+//
+// Since 0.5.11
+func (st *SlimTrie) MarshalAligned() ([]byte, error) {
+
+	w := &alignedWriter{}
+	w.buf = append(w.buf, alignedMagic...)
+	w.u64(alignedVersion)
+	w.bytes([]byte(encoderName(st.encoder)))
+	w.nodes(st.nodes)
+
+	return w.buf, nil
+}
+
+// UnmarshalNoCopy loads a SlimTrie from data serialized by MarshalAligned().
+//
+// The loaded SlimTrie references buf, instead of copying it.
+// Thus buf must not be modified or released as long as the SlimTrie is used.
+//
+// If buf is not 8-byte aligned, or on a big-endian machine, it falls back to
+// copying the data.
+//
+// Just like Unmarshal(), it returns ErrEncoderMismatch if the encoder of the
+// SlimTrie is not the one recorded in buf.
+//
+// Since 0.5.11
+func (st *SlimTrie) UnmarshalNoCopy(buf []byte) error {
+
+	if len(buf) > 0 && uintptr(unsafe.Pointer(&buf[0]))&7 != 0 {
+		aligned := make([]uint64, (len(buf)+7)/8)
+		b := u64sAsBytes(aligned)[:len(buf)]
+		copy(b, buf)
+		buf = b
+	}
+
+	if len(buf) < len(alignedMagic)+8 || string(buf[:len(alignedMagic)]) != alignedMagic {
+		return errors.Wrapf(ErrMalformed, "no aligned format magic")
+	}
+
+	r := &alignedReader{buf: buf, pos: len(alignedMagic)}
+
+	ver := r.u64()
+	if ver != alignedVersion {
+		return errors.Wrapf(ErrIncompatible, "aligned format version: %d, want: %d", ver, alignedVersion)
+	}
+
+	name := r.bytes()
+	ns := r.nodes()
+	if r.err != nil {
+		return r.err
+	}
+
+	err := st.setEncoder(string(name))
+	if err != nil {
+		return err
+	}
+
+	st.nodes = ns
+
+	if st.validateOnLoad {
+		err := st.Validate()
+		if err != nil {
+			st.nodes = &Nodes{}
+			return err
+		}
+	}
+
+	return nil
+}
+
+type alignedWriter struct {
+	buf []byte
+}
+
+func (w *alignedWriter) u64(v uint64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], v)
+	w.buf = append(w.buf, b[:]...)
+}
+
+func (w *alignedWriter) i32(v int32) {
+	w.u64(uint64(uint32(v)))
+}
+
+func (w *alignedWriter) pad() {
+	for len(w.buf)&7 != 0 {
+		w.buf = append(w.buf, 0)
+	}
+}
+
+func (w *alignedWriter) bytes(b []byte) {
+	w.u64(uint64(len(b)))
+	w.buf = append(w.buf, b...)
+	w.pad()
+}
+
+func (w *alignedWriter) u64s(s []uint64) {
+	w.u64(uint64(len(s)))
+	for _, v := range s {
+		w.u64(v)
+	}
+}
+
+func (w *alignedWriter) u32s(s []uint32) {
+	w.u64(uint64(len(s)))
+	var b [4]byte
+	for _, v := range s {
+		binary.LittleEndian.PutUint32(b[:], v)
+		w.buf = append(w.buf, b[:]...)
+	}
+	w.pad()
+}
+
+func (w *alignedWriter) i32s(s []int32) {
+	w.u64(uint64(len(s)))
+	var b [4]byte
+	for _, v := range s {
+		binary.LittleEndian.PutUint32(b[:], uint32(v))
+		w.buf = append(w.buf, b[:]...)
+	}
+	w.pad()
+}
+
+func (w *alignedWriter) bitmap(bm *Bitmap) {
+	if bm == nil {
+		w.u64(0)
+		return
+	}
+	w.u64(1)
+	w.u64s(bm.Words)
+	w.i32s(bm.RankIndex)
+	w.i32s(bm.SelectIndex)
+}
+
+func (w *alignedWriter) vlenArray(a *VLenArray) {
+	if a == nil {
+		w.u64(0)
+		return
+	}
+	w.u64(1)
+	w.i32(a.N)
+	w.i32(a.EltCnt)
+	w.i32(a.FixedSize)
+	w.bitmap(a.PresenceBM)
+	w.bitmap(a.PositionBM)
+	w.bytes(a.Bytes)
+}
+
+func (w *alignedWriter) buildOptions(o *BuildOptions) {
+	if o == nil {
+		w.u64(0)
+		return
+	}
+	w.u64(1)
+	w.bools(o.DedupValue, o.InnerPrefix, o.LeafPrefix, o.Complete)
+}
+
+// bools writes flags as bits of one integer.
+func (w *alignedWriter) bools(flags ...bool) {
+	v := uint64(0)
+	for i, f := range flags {
+		if f {
+			v |= 1 << uint(i)
+		}
+	}
+	w.u64(v)
+}
+
+func (w *alignedWriter) nodes(ns *Nodes) {
+	w.i32(ns.BigInnerCnt)
+	w.i32(ns.BigInnerOffset)
+	w.i32(ns.ShortMinusInner)
+	w.i32(ns.ShortSize)
+	w.u64(ns.ShortMask)
+	w.i32(ns.FingerprintBits)
+
+	w.bitmap(ns.NodeTypeBM)
+	w.bitmap(ns.Inners)
+	w.bitmap(ns.ShortBM)
+	w.u32s(ns.ShortTable)
+	w.vlenArray(ns.InnerPrefixes)
+	w.vlenArray(ns.LeafPrefixes)
+	w.vlenArray(ns.Leaves)
+	w.bitmap(ns.Fingerprints)
+	w.buildOptions(ns.BuildOptions)
+}
+
+// alignedReader reads fields in the same order alignedWriter writes.
+// After an error, it returns zero values and keeps the first error in err.
+type alignedReader struct {
+	buf []byte
+	pos int
+	err error
+}
+
+// next returns the next n bytes, padded to 8 bytes.
+func (r *alignedReader) next(n int) []byte {
+
+	if r.err != nil {
+		return nil
+	}
+
+	padded := (n + 7) &^ 7
+	if n < 0 || padded > len(r.buf)-r.pos {
+		r.err = errors.Wrapf(ErrMalformed, "need %d bytes at %d, buf size: %d", n, r.pos, len(r.buf))
+		return nil
+	}
+
+	b := r.buf[r.pos : r.pos+n : r.pos+n]
+	r.pos += padded
+	return b
+}
+
+func (r *alignedReader) u64() uint64 {
+	b := r.next(8)
+	if b == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint64(b)
+}
+
+func (r *alignedReader) i32() int32 {
+	return int32(uint32(r.u64()))
+}
+
+// length reads the length of a slice, and checks if there is enough data for
+// it.
+func (r *alignedReader) length(eltSize int) int {
+	n := r.u64()
+	if n > uint64(len(r.buf)-r.pos)/uint64(eltSize) {
+		if r.err == nil {
+			r.err = errors.Wrapf(ErrMalformed, "slice length %d at %d, buf size: %d", n, r.pos, len(r.buf))
+		}
+		return 0
+	}
+	return int(n)
+}
+
+func (r *alignedReader) bytes() []byte {
+	n := r.length(1)
+	if n == 0 {
+		return nil
+	}
+	return r.next(n)
+}
+
+func (r *alignedReader) u64s() []uint64 {
+	n := r.length(8)
+	if n == 0 {
+		return nil
+	}
+	b := r.next(n * 8)
+	if b == nil {
+		return nil
+	}
+
+	if isLittleEndian {
+		return bytesAsU64s(b)
+	}
+
+	s := make([]uint64, n)
+	for i := range s {
+		s[i] = binary.LittleEndian.Uint64(b[i*8:])
+	}
+	return s
+}
+
+func (r *alignedReader) u32s() []uint32 {
+	n := r.length(4)
+	if n == 0 {
+		return nil
+	}
+	b := r.next(n * 4)
+	if b == nil {
+		return nil
+	}
+
+	if isLittleEndian {
+		return bytesAsU32s(b)
+	}
+
+	s := make([]uint32, n)
+	for i := range s {
+		s[i] = binary.LittleEndian.Uint32(b[i*4:])
+	}
+	return s
+}
+
+func (r *alignedReader) i32s() []int32 {
+	s := r.u32s()
+	if s == nil {
+		return nil
+	}
+	return *(*[]int32)(unsafe.Pointer(&s))
+}
+
+func (r *alignedReader) bitmap() *Bitmap {
+	if r.u64() == 0 {
+		return nil
+	}
+	return &Bitmap{
+		Words:       r.u64s(),
+		RankIndex:   r.i32s(),
+		SelectIndex: r.i32s(),
+	}
+}
+
+func (r *alignedReader) vlenArray() *VLenArray {
+	if r.u64() == 0 {
+		return nil
+	}
+	return &VLenArray{
+		N:          r.i32(),
+		EltCnt:     r.i32(),
+		FixedSize:  r.i32(),
+		PresenceBM: r.bitmap(),
+		PositionBM: r.bitmap(),
+		Bytes:      r.bytes(),
+	}
+}
+
+func (r *alignedReader) buildOptions() *BuildOptions {
+	if r.u64() == 0 {
+		return nil
+	}
+	v := r.u64()
+	return &BuildOptions{
+		DedupValue:  v&1 != 0,
+		InnerPrefix: v&2 != 0,
+		LeafPrefix:  v&4 != 0,
+		Complete:    v&8 != 0,
+	}
+}
+
+func (r *alignedReader) nodes() *Nodes {
+	return &Nodes{
+		BigInnerCnt:     r.i32(),
+		BigInnerOffset:  r.i32(),
+		ShortMinusInner: r.i32(),
+		ShortSize:       r.i32(),
+		ShortMask:       r.u64(),
+		FingerprintBits: r.i32(),
+
+		NodeTypeBM:    r.bitmap(),
+		Inners:        r.bitmap(),
+		ShortBM:       r.bitmap(),
+		ShortTable:    r.u32s(),
+		InnerPrefixes: r.vlenArray(),
+		LeafPrefixes:  r.vlenArray(),
+		Leaves:        r.vlenArray(),
+		Fingerprints:  r.bitmap(),
+		BuildOptions:  r.buildOptions(),
+	}
+}
+
+// bytesAsU64s views an 8-byte aligned []byte as []uint64 without copy.
+func bytesAsU64s(b []byte) []uint64 {
+	var s []uint64
+	h := (*reflect.SliceHeader)(unsafe.Pointer(&s))
+	h.Data = uintptr(unsafe.Pointer(&b[0]))
+	h.Len = len(b) / 8
+	h.Cap = len(b) / 8
+	return s
+}
+
+// bytesAsU32s views a 4-byte aligned []byte as []uint32 without copy.
+func bytesAsU32s(b []byte) []uint32 {
+	var s []uint32
+	h := (*reflect.SliceHeader)(unsafe.Pointer(&s))
+	h.Data = uintptr(unsafe.Pointer(&b[0]))
+	h.Len = len(b) / 4
+	h.Cap = len(b) / 4
+	return s
+}
+
+// u64sAsBytes views a []uint64 as []byte without copy.
+func u64sAsBytes(s []uint64) []byte {
+	var b []byte
+	if len(s) == 0 {
+		return b
+	}
+	h := (*reflect.SliceHeader)(unsafe.Pointer(&b))
+	h.Data = uintptr(unsafe.Pointer(&s[0]))
+	h.Len = len(s) * 8
+	h.Cap = len(s) * 8
+	return b
+}
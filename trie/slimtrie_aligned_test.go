@@ -0,0 +1,235 @@
+package trie
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"unsafe"
+
+	"github.com/openacid/errors"
+	"github.com/openacid/slim/encode"
+	"github.com/openacid/testkeys"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_UnmarshalNoCopy(t *testing.T) {
+
+	ta := require.New(t)
+
+	opts := []Opt{
+		{},
+		{InnerPrefix: Bool(true)},
+		{Complete: Bool(true)},
+		{FingerprintBits: 8},
+	}
+
+	for _, typ := range testkeys.AssetNames() {
+
+		keys := getKeys(typ)
+		if len(keys) >= 1000 {
+			continue
+		}
+		values := makeI32s(len(keys))
+
+		for _, opt := range opts {
+
+			st1, err := NewSlimTrie(encode.I32{}, keys, values, opt)
+			ta.NoError(err)
+
+			buf, err := st1.MarshalAligned()
+			ta.NoError(err)
+
+			st2, err := NewSlimTrie(encode.I32{}, nil, nil)
+			ta.NoError(err)
+			ta.NoError(st2.UnmarshalNoCopy(buf))
+
+			slimtrieEqual(st1, st2, t)
+			testPresentKeysGet(t, st2, keys, values)
+
+			// filter mode
+
+			st1, err = NewSlimTrie(nil, keys, nil, opt)
+			ta.NoError(err)
+
+			buf, err = st1.MarshalAligned()
+			ta.NoError(err)
+
+			st2 = &SlimTrie{}
+			ta.NoError(st2.UnmarshalNoCopy(buf))
+			slimtrieEqual(st1, st2, t)
+		}
+	}
+}
+
+func TestSlimTrie_UnmarshalNoCopy_noCopy(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+
+	st1, err := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	buf, err := st1.MarshalAligned()
+	ta.NoError(err)
+
+	// make sure buf is aligned
+	aligned := u64sAsBytes(make([]uint64, (len(buf)+7)/8))[:len(buf)]
+	copy(aligned, buf)
+
+	st2 := &SlimTrie{encoder: encode.I32{}}
+	ta.NoError(st2.UnmarshalNoCopy(aligned))
+
+	inBuf := func(p unsafe.Pointer) bool {
+		a := uintptr(p)
+		start := uintptr(unsafe.Pointer(&aligned[0]))
+		return a >= start && a < start+uintptr(len(aligned))
+	}
+
+	ns := st2.nodes
+	ta.True(inBuf(unsafe.Pointer(&ns.Inners.Words[0])))
+	ta.True(inBuf(unsafe.Pointer(&ns.Inners.RankIndex[0])))
+	ta.True(inBuf(unsafe.Pointer(&ns.ShortTable[0])))
+	ta.True(inBuf(unsafe.Pointer(&ns.LeafPrefixes.Bytes[0])))
+	ta.True(inBuf(unsafe.Pointer(&ns.Leaves.Bytes[0])))
+
+	// unaligned buffer is copied
+
+	unaligned := append(make([]byte, 1), buf...)[1:]
+	st3 := &SlimTrie{encoder: encode.I32{}}
+	ta.NoError(st3.UnmarshalNoCopy(unaligned))
+	slimtrieEqual(st1, st3, t)
+}
+
+func TestSlimTrie_UnmarshalNoCopy_malformed(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("10vl5")
+	st1, err := NewSlimTrie(encode.I32{}, keys, makeI32s(len(keys)))
+	ta.NoError(err)
+
+	buf, err := st1.MarshalAligned()
+	ta.NoError(err)
+
+	st2 := &SlimTrie{encoder: encode.I32{}}
+
+	for _, n := range []int{0, 4, 16, 40, len(buf) / 2, len(buf) - 8} {
+		err = st2.UnmarshalNoCopy(buf[:n])
+		ta.Equal(ErrMalformed, errors.Cause(err), "size: %d", n)
+	}
+
+	// protobuf format is not accepted
+	pb, err := st1.Marshal()
+	ta.NoError(err)
+	err = st2.UnmarshalNoCopy(pb)
+	ta.Equal(ErrMalformed, errors.Cause(err))
+
+	// unknown format version
+	b := append([]byte{}, buf...)
+	b[len(alignedMagic)] = 2
+	err = st2.UnmarshalNoCopy(b)
+	ta.Equal(ErrIncompatible, errors.Cause(err))
+}
+
+func TestOpen(t *testing.T) {
+
+	ta := require.New(t)
+
+	dir, err := ioutil.TempDir("", "slimtrie")
+	ta.NoError(err)
+	defer os.RemoveAll(dir)
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+
+	st1, err := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	buf, err := st1.MarshalAligned()
+	ta.NoError(err)
+
+	path := filepath.Join(dir, "st")
+	ta.NoError(ioutil.WriteFile(path, buf, 0644))
+
+	st2, err := Open(path, encode.I32{})
+	ta.NoError(err)
+
+	slimtrieEqual(st1, st2, t)
+	testPresentKeysGet(t, st2, keys, values)
+
+	ta.NoError(st2.Close())
+	ta.Equal(int32(-1), st2.GetID(keys[0]))
+
+	// close a SlimTrie not opened
+	ta.NoError(st1.Close())
+
+	// errors
+
+	_, err = Open(filepath.Join(dir, "nonexistent"), encode.I32{})
+	ta.Error(err)
+
+	empty := filepath.Join(dir, "empty")
+	ta.NoError(ioutil.WriteFile(empty, nil, 0644))
+	_, err = Open(empty, encode.I32{})
+	ta.Equal(ErrMalformed, errors.Cause(err))
+
+	broken := filepath.Join(dir, "broken")
+	ta.NoError(ioutil.WriteFile(broken, buf[:len(buf)/2], 0644))
+	_, err = Open(broken, encode.I32{})
+	ta.Equal(ErrMalformed, errors.Cause(err))
+}
+
+func TestOpen_encoder(t *testing.T) {
+
+	ta := require.New(t)
+
+	dir, err := ioutil.TempDir("", "slimtrie")
+	ta.NoError(err)
+	defer os.RemoveAll(dir)
+
+	keys := getKeys("10vl5")
+	values := makeI32s(len(keys))
+
+	st1, err := NewSlimTrie(encode.I32{}, keys, values)
+	ta.NoError(err)
+
+	buf, err := st1.MarshalAligned()
+	ta.NoError(err)
+
+	path := filepath.Join(dir, "st")
+	ta.NoError(ioutil.WriteFile(path, buf, 0644))
+
+	_, err = Open(path, encode.U32{})
+	ta.Equal(ErrEncoderMismatch, errors.Cause(err))
+
+	st2 := &SlimTrie{encoder: encode.U32{}}
+	err = st2.UnmarshalNoCopy(buf)
+	ta.Equal(ErrEncoderMismatch, errors.Cause(err))
+
+	// the encoder recorded in file is used
+
+	st3, err := Open(path, nil)
+	ta.NoError(err)
+	defer st3.Close()
+
+	ta.Equal(encode.I32{}, st3.encoder)
+	testPresentKeysGet(t, st3, keys, values)
+
+	// no encoder name recorded
+
+	te, err := encode.NewTypeEncoder(int32(0))
+	ta.NoError(err)
+
+	st4, err := NewSlimTrie(te, keys, values)
+	ta.NoError(err)
+
+	buf, err = st4.MarshalAligned()
+	ta.NoError(err)
+	ta.NoError(ioutil.WriteFile(path, buf, 0644))
+
+	_, err = Open(path, nil)
+	ta.Equal(encode.ErrUnknownEncoder, errors.Cause(err))
+}
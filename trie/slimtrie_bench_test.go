@@ -57,3 +57,116 @@ func BenchmarkSlimTrie_withPrefixContent_GetID_20k_vlen10(b *testing.B) {
 		}
 	}
 }
+
+func BenchmarkSlimTrie_GetManyID_20k_vlen10(b *testing.B) {
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+	st, _ := NewSlimTrie(encode.I32{}, keys, values)
+
+	ids := make([]int32, len(keys))
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i += len(keys) {
+		st.GetManyID(keys, ids)
+	}
+	Outputxxx = ids[0]
+}
+
+func BenchmarkQuerier_GetID_20k_vlen10(b *testing.B) {
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+	st, _ := NewSlimTrie(encode.I32{}, keys, values)
+	q := st.NewQuerier()
+
+	var id int32
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		id += q.GetID(keys[i%len(keys)])
+	}
+	Outputxxx = id
+}
+
+func BenchmarkQuerier_GetValueBytes_20k_vlen10(b *testing.B) {
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+	st, _ := NewSlimTrie(encode.I32{}, keys, values)
+	q := st.NewQuerier()
+
+	var s int32
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		v, _ := q.GetValueBytes(keys[i%len(keys)])
+		s += int32(v[0])
+	}
+	Outputxxx = s
+}
+
+func BenchmarkQuerier_SearchID_20k_vlen10(b *testing.B) {
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+	st, _ := NewSlimTrie(encode.I32{}, keys, values)
+	q := st.NewQuerier()
+
+	var id int32
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		l, _, _ := q.SearchID(keys[i%len(keys)])
+		id += l
+	}
+	Outputxxx = id
+}
+
+func BenchmarkQuerier_Count_20k_vlen10(b *testing.B) {
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+	st, _ := NewSlimTrie(encode.I32{}, keys, values)
+	q := st.NewQuerier()
+
+	var n int64
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		n += q.Count(keys[i%len(keys)], keys[len(keys)/2])
+	}
+	Outputxxx = int32(n)
+}
+
+func BenchmarkQuerier_Scan_100_20k_vlen10(b *testing.B) {
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+	st, _ := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+	q := st.NewQuerier()
+
+	var n int32
+	fn := func(key, value []byte) bool {
+		n++
+		return true
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		j := i % (len(keys) - 100)
+		q.Scan(keys[j], keys[j+100], fn)
+	}
+	Outputxxx = n
+}
@@ -0,0 +1,152 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/openacid/errors"
+	"github.com/openacid/low/sigbits"
+	"github.com/openacid/slim/encode"
+	"github.com/openacid/testkeys"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder(t *testing.T) {
+
+	ta := require.New(t)
+
+	opts := []Opt{
+		{},
+		{DedupValue: Bool(false)},
+		{InnerPrefix: Bool(true)},
+		{Complete: Bool(true)},
+	}
+
+	for _, typ := range testkeys.AssetNames() {
+
+		keys := getKeys(typ)
+		if len(keys) >= 1000 {
+			continue
+		}
+
+		// adjacent keys with the same value to test DedupValue
+		values := makeI32s(len(keys))
+		for i := range values {
+			values[i] /= 3
+		}
+
+		for _, opt := range opts {
+
+			want, err := NewSlimTrie(encode.I32{}, keys, values, opt)
+			ta.NoError(err)
+
+			b := NewBuilder(encode.I32{}, opt)
+			for i, k := range keys {
+				ta.NoError(b.Add(k, values[i]))
+			}
+			st, err := b.Build()
+			ta.NoError(err)
+
+			slimtrieEqual(want, st, t)
+
+			// filter mode
+
+			want, err = NewSlimTrie(nil, keys, nil, opt)
+			ta.NoError(err)
+
+			b = NewBuilder(nil, opt)
+			for _, k := range keys {
+				ta.NoError(b.Add(k, nil))
+			}
+			st, err = b.Build()
+			ta.NoError(err)
+
+			slimtrieEqual(want, st, t)
+		}
+	}
+}
+
+func TestBuilder_varLenValues(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+
+	b := NewBuilder(encode.String16{}, Opt{Complete: Bool(true)})
+	for _, k := range keys {
+		ta.NoError(b.Add(k, k))
+	}
+	st, err := b.Build()
+	ta.NoError(err)
+
+	for _, k := range keys {
+		v, found := st.Get(k)
+		ta.True(found)
+		ta.Equal(k, v)
+	}
+}
+
+func TestBuilder_outOfOrder(t *testing.T) {
+
+	ta := require.New(t)
+
+	b := NewBuilder(encode.I32{})
+	ta.NoError(b.Add("b", int32(1)))
+
+	err := b.Add("a", int32(2))
+	ta.Equal(ErrKeyOutOfOrder, errors.Cause(err))
+
+	err = b.Add("b", int32(2))
+	ta.Equal(ErrKeyOutOfOrder, errors.Cause(err))
+
+	// the failed keys are not added
+	ta.NoError(b.Add("c", int32(3)))
+
+	st, err := b.Build()
+	ta.NoError(err)
+
+	v, found := st.Get("b")
+	ta.True(found)
+	ta.Equal(int32(1), v)
+
+	v, found = st.Get("c")
+	ta.True(found)
+	ta.Equal(int32(3), v)
+}
+
+func TestBuilder_reuse(t *testing.T) {
+
+	ta := require.New(t)
+
+	b := NewBuilder(encode.I32{})
+
+	st, err := b.Build()
+	ta.NoError(err)
+	ta.Equal(int32(-1), st.GetID("a"))
+
+	ta.NoError(b.Add("b", int32(1)))
+	_, err = b.Build()
+	ta.NoError(err)
+
+	// keys added before the last Build are cleared
+	ta.NoError(b.Add("a", int32(2)))
+	st, err = b.Build()
+	ta.NoError(err)
+
+	v, found := st.Get("a")
+	ta.True(found)
+	ta.Equal(int32(2), v)
+}
+
+func TestBuilder_firstDiffBit(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := []string{"", "\x00", "\x00\x00", "a", "ab", "abc", "abd", "b", "ba"}
+	keys = append(keys, getKeys("20kvl10")...)
+
+	for i := 0; i+1 < len(keys); i++ {
+		a, b := keys[i], keys[i+1]
+		want := sigbits.FirstDiffBits([]string{a, b})[0]
+		ta.Equal(want, firstDiffBit(a, b), "%q %q", a, b)
+	}
+}
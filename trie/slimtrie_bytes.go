@@ -0,0 +1,69 @@
+package trie
+
+import "unsafe"
+
+// Query methods in this file accept a key in []byte.
+//
+// The key is viewed as a string without copy thus there is no allocation.
+// The key must not be modified during a query.
+
+// GetBytesKey is same as Get() except the key is a []byte.
+//
+// Since 0.5.11
+func (st *SlimTrie) GetBytesKey(key []byte) (interface{}, bool) {
+	return st.Get(bytesToStr(key))
+}
+
+// GetIDBytes is same as GetID() except the key is a []byte.
+//
+// Since 0.5.11
+func (st *SlimTrie) GetIDBytes(key []byte) int32 {
+	return st.GetID(bytesToStr(key))
+}
+
+// RangeGetBytes is same as RangeGet() except the key is a []byte.
+//
+// Since 0.5.11
+func (st *SlimTrie) RangeGetBytes(key []byte) (interface{}, bool) {
+	return st.RangeGet(bytesToStr(key))
+}
+
+// SearchBytes is same as Search() except the key is a []byte.
+//
+// Since 0.5.11
+func (st *SlimTrie) SearchBytes(key []byte) (lVal, eqVal, rVal interface{}) {
+	return st.Search(bytesToStr(key))
+}
+
+// GetI8Bytes is same as GetI8() except the key is a []byte.
+//
+// Since 0.5.11
+func (st *SlimTrie) GetI8Bytes(key []byte) (int8, bool) {
+	return st.GetI8(bytesToStr(key))
+}
+
+// GetI16Bytes is same as GetI16() except the key is a []byte.
+//
+// Since 0.5.11
+func (st *SlimTrie) GetI16Bytes(key []byte) (int16, bool) {
+	return st.GetI16(bytesToStr(key))
+}
+
+// GetI32Bytes is same as GetI32() except the key is a []byte.
+//
+// Since 0.5.11
+func (st *SlimTrie) GetI32Bytes(key []byte) (int32, bool) {
+	return st.GetI32(bytesToStr(key))
+}
+
+// GetI64Bytes is same as GetI64() except the key is a []byte.
+//
+// Since 0.5.11
+func (st *SlimTrie) GetI64Bytes(key []byte) (int64, bool) {
+	return st.GetI64(bytesToStr(key))
+}
+
+// bytesToStr returns a string sharing memory with b.
+func bytesToStr(b []byte) string {
+	return *(*string)(unsafe.Pointer(&b))
+}
@@ -0,0 +1,122 @@
+package trie
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/openacid/slim/encode"
+	"github.com/openacid/testkeys"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_BytesKey(t *testing.T) {
+
+	ta := require.New(t)
+
+	opts := []Opt{
+		{},
+		{InnerPrefix: Bool(true)},
+		{Complete: Bool(true)},
+	}
+
+	for _, typ := range testkeys.AssetNames() {
+
+		keys := getKeys(typ)
+		if len(keys) >= 1000 {
+			continue
+		}
+
+		values := makeI32s(len(keys))
+		queries := append(makeAbsentKeys(keys, len(keys), 0, 20), keys...)
+
+		for _, opt := range opts {
+
+			st, err := NewSlimTrie(encode.I32{}, keys, values, opt)
+			ta.NoError(err)
+
+			for _, key := range queries {
+
+				bk := []byte(key)
+				msg := []interface{}{"keys: %s, opt: %+v, key: %q", typ, opt, key}
+
+				ta.Equal(st.GetID(key), st.GetIDBytes(bk), msg...)
+
+				v, found := st.Get(key)
+				bv, bfound := st.GetBytesKey(bk)
+				ta.Equal(v, bv, msg...)
+				ta.Equal(found, bfound, msg...)
+
+				v, found = st.RangeGet(key)
+				bv, bfound = st.RangeGetBytes(bk)
+				ta.Equal(v, bv, msg...)
+				ta.Equal(found, bfound, msg...)
+
+				l, eq, r := st.Search(key)
+				bl, beq, br := st.SearchBytes(bk)
+				ta.Equal([]interface{}{l, eq, r}, []interface{}{bl, beq, br}, msg...)
+
+				i32, found := st.GetI32(key)
+				bi32, bfound := st.GetI32Bytes(bk)
+				ta.Equal(i32, bi32, msg...)
+				ta.Equal(found, bfound, msg...)
+			}
+		}
+	}
+}
+
+func TestSlimTrie_BytesKey_noAlloc(t *testing.T) {
+
+	ta := require.New(t)
+
+	// long common prefixes and long leaf prefixes force comparisons of more
+	// than 32 bytes.
+	pref := strings.Repeat("x", 40)
+	keys := []string{}
+	for _, k := range randVStrings(1000, 40, 80) {
+		keys = append(keys, pref+k)
+	}
+	sort.Strings(keys)
+	values := makeI32s(len(keys))
+
+	queries := append(makeAbsentKeys(keys, 100, 40, 120), keys...)
+	bqueries := make([][]byte, len(queries))
+	for i, k := range queries {
+		bqueries[i] = []byte(k)
+	}
+
+	for _, opt := range []Opt{{InnerPrefix: Bool(true)}, {Complete: Bool(true)}} {
+
+		st, err := NewSlimTrie(encode.I32{}, keys, values, opt)
+		ta.NoError(err)
+
+		allocs := testing.AllocsPerRun(10, func() {
+			for _, k := range bqueries {
+				st.GetIDBytes(k)
+				st.GetI32Bytes(k)
+			}
+		})
+		ta.Equal(float64(0), allocs, "opt: %+v", opt)
+	}
+}
+
+func TestCmpStrBytes(t *testing.T) {
+
+	ta := require.New(t)
+
+	cases := []struct {
+		s, b string
+		want int
+	}{
+		{"", "", 0},
+		{"a", "", 1},
+		{"", "a", -1},
+		{"ab", "ab", 0},
+		{"ab", "abc", -1},
+		{"abd", "abc", 1},
+	}
+
+	for i, c := range cases {
+		ta.Equal(c.want, cmpStrBytes(c.s, []byte(c.b)), "%d-th: case: %+v", i+1, c)
+	}
+}
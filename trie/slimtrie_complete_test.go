@@ -183,6 +183,26 @@ func TestSlimTrie_Complete_GRS_1_twokeys_emptysteps(t *testing.T) {
 	testPresentKeysGRS(t, st, keys, values)
 }
 
+func TestSlimTrie_Complete_GRS_1_filterMode(t *testing.T) {
+
+	// In filter mode the leaf prefix bitmap must cover all leaves.
+
+	ta := require.New(t)
+
+	keys := []string{"a", "b", "c"}
+
+	st, err := NewSlimTrie(nil, keys, nil, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	for _, k := range keys {
+		_, found := st.Get(k)
+		ta.True(found, "key: %s", k)
+	}
+
+	_, found := st.Get("d")
+	ta.False(found)
+}
+
 func TestSlimTrie_Complete_GRS_2_small_keyset(t *testing.T) {
 
 	ta := require.New(t)
@@ -0,0 +1,121 @@
+package trie
+
+import "github.com/openacid/low/bitmap"
+
+// Count returns the number of keys in range ["from", "to").
+// An empty "to" means there is no upper bound.
+//
+// It does not iterate keys: nodes of the same depth are stored in key order,
+// thus on every level, keys before a leaf are counted with a rank on
+// NodeTypeBM.
+// The cost is proportional to the height of SlimTrie.
+//
+// It counts leaves, thus a key removed by Opt.DedupValue is not counted.
+// To count all keys, create SlimTrie with DedupValue=false, or in filter mode.
+//
+// With Opt.Complete the result is exact.
+// Otherwise range bounds are located the same way Search() does, and the
+// result is an approximation: it may include a few keys just out of the
+// range, or miss a few keys just in it.
+//
+// Since 0.5.11
+func (st *SlimTrie) Count(from, to string) int64 {
+	n, _ := st.count(from, to, nil)
+	return n
+}
+
+// count is the implementation of Count.
+// "path" is a buffer to store a path, it is returned for reuse.
+func (st *SlimTrie) count(from, to string, path []int32) (int64, []int32) {
+
+	if st.nodes.NodeTypeBM == nil {
+		return 0, path
+	}
+
+	var n, m int64
+
+	n, path = st.countLess(from, path)
+
+	m = int64(st.LeafCnt())
+	if to != "" {
+		m, path = st.countLess(to, path)
+	}
+
+	if m < n {
+		return 0, path
+	}
+	return m - n, path
+}
+
+// countLess returns the number of leaves before key.
+func (st *SlimTrie) countLess(key string, path []int32) (int64, []int32) {
+
+	lID, _, _ := st.searchID(key)
+	if lID == -1 {
+		return 0, path
+	}
+
+	path = st.appendPath(path[:0], lID)
+	return st.keyRank(path) + 1, path
+}
+
+// keyRank returns the number of leaves before a leaf, in key order.
+// "path" is the node ids from root to the leaf.
+//
+// Nodes of every depth are numbered in key order.
+// On every level, leaves before the path are those before the "frontier":
+// the node on the path, or the first child of nodes on the upper level after
+// the frontier, for levels below the leaf.
+func (st *SlimTrie) keyRank(path []int32) int64 {
+
+	nodeCnt := st.nodeCnt()
+
+	cnt := int64(0)
+
+	levelStart := int32(0)
+	frontier := int32(0)
+
+	for d := 0; levelStart < nodeCnt; d++ {
+
+		if d < len(path) {
+			frontier = path[d]
+		}
+
+		cnt += int64(st.leafCntBefore(frontier) - st.leafCntBefore(levelStart))
+
+		levelStart = st.firstChildOf(levelStart)
+		frontier = st.firstChildOf(frontier)
+	}
+
+	return cnt
+}
+
+// innerCntBefore returns the number of inner nodes with id < nodeid.
+func (st *SlimTrie) innerCntBefore(nodeid int32) int32 {
+	if nodeid >= st.nodeCnt() {
+		return st.innerCnt()
+	}
+	ns := st.nodes
+	r, _ := bitmap.Rank64(ns.NodeTypeBM.Words, ns.NodeTypeBM.RankIndex, nodeid)
+	return r
+}
+
+// leafCntBefore returns the number of leaves with id < nodeid.
+func (st *SlimTrie) leafCntBefore(nodeid int32) int32 {
+	return nodeid - st.innerCntBefore(nodeid)
+}
+
+// firstChildOf returns the id of the first child of the first inner node with
+// id >= nodeid.
+// It returns the number of nodes if there is no such inner node.
+func (st *SlimTrie) firstChildOf(nodeid int32) int32 {
+
+	ithInner := st.innerCntBefore(nodeid)
+	if ithInner == st.innerCnt() {
+		return st.nodeCnt()
+	}
+
+	ns := st.nodes
+	r, _ := bitmap.Rank128(ns.Inners.Words, ns.Inners.RankIndex, st.innerFrom(ithInner))
+	return r + 1
+}
@@ -0,0 +1,117 @@
+package trie
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/openacid/slim/encode"
+	"github.com/openacid/testkeys"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_Count(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := []string{"a", "ab", "abc", "abd", "b", "bc", "c"}
+
+	st, err := NewSlimTrie(encode.I32{}, keys, makeI32s(len(keys)), Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	cases := []struct {
+		from, to string
+		want     int64
+	}{
+		{"", "", 7},
+		{"a", "", 7},
+		{"", "a", 0},
+		{"a", "b", 4},
+		{"ab", "abd", 2},
+		{"ab", "abda", 3},
+		{"aa", "bb", 4},
+		{"b", "a", 0},
+		{"c", "", 1},
+		{"ca", "", 0},
+	}
+
+	for i, c := range cases {
+		got := st.Count(c.from, c.to)
+		ta.Equal(c.want, got, "%d-th: case: %+v", i+1, c)
+	}
+
+	// empty
+
+	st, err = NewSlimTrie(encode.I32{}, nil, nil)
+	ta.NoError(err)
+	ta.Equal(int64(0), st.Count("", ""))
+}
+
+func TestSlimTrie_Count_Complete(t *testing.T) {
+
+	ta := require.New(t)
+
+	for _, typ := range testkeys.AssetNames() {
+
+		keys := getKeys(typ)
+		if len(keys) == 0 || len(keys) >= 1000 {
+			continue
+		}
+
+		bounds := append(makeAbsentKeys(keys, len(keys), 0, 20), keys...)
+
+		for _, e := range []encode.Encoder{encode.I32{}, nil} {
+
+			var values interface{}
+			if e != nil {
+				values = makeI32s(len(keys))
+			}
+
+			st, err := NewSlimTrie(e, keys, values, Opt{Complete: Bool(true), DedupValue: Bool(false)})
+			ta.NoError(err)
+
+			for i := 0; i < 2000; i++ {
+				from := bounds[rand.Intn(len(bounds))]
+				to := bounds[rand.Intn(len(bounds))]
+
+				end := len(keys)
+				if to != "" {
+					end = sort.SearchStrings(keys, to)
+				}
+
+				want := int64(end - sort.SearchStrings(keys, from))
+				if want < 0 {
+					want = 0
+				}
+
+				got := st.Count(from, to)
+				ta.Equal(want, got, "keys: %s, from: %q, to: %q", typ, from, to)
+			}
+		}
+	}
+}
+
+func TestSlimTrie_Count_presentKeys(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+
+	for _, opt := range []Opt{{}, {InnerPrefix: Bool(true)}, {LeafPrefix: Bool(true)}} {
+
+		st, err := NewSlimTrie(encode.I32{}, keys, values, opt)
+		ta.NoError(err)
+
+		// bounds that are present keys are located exactly.
+		for i := 0; i < 1000; i++ {
+			a := rand.Intn(len(keys))
+			b := a + rand.Intn(len(keys)-a)
+
+			got := st.Count(keys[a], keys[b])
+			ta.Equal(int64(b-a), got, "opt: %+v, from: %q, to: %q", opt, keys[a], keys[b])
+		}
+
+		ta.Equal(int64(len(keys)), st.Count("", ""))
+	}
+}
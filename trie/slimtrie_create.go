@@ -59,19 +59,29 @@ type creator struct {
 	prefixes       []byte
 
 	// len: nr of leaf nodes = len(nodes) - len(inner_nodes)
-	leaves [][]byte
+	//
+	// Encoded values of all leaves are concatenated into leafValues.
+	leafValues    []byte
+	leafValueLens []int32
 
 	leafPrefixIndexes []int32
 	leafPrefixLens    []int32
 	leafPrefixes      []byte
-	prefs             map[string]int32
+
+	// fingerprints of all leaves, Opt.FingerprintBits bits each.
+	fingerprints []uint64
 
 	// stats those affect creating
 
 	innerBMCnt []map[uint64]int32
 }
 
-func newCreator(n int, withLeaves bool, opt *Opt) *creator {
+// newCreator creates a creator.
+//
+// Buffers are not pre-allocated with the number of keys: the number of inner
+// nodes and leaves is usually much smaller and pre-allocating costs too much
+// memory when creating from a large key set.
+func newCreator(withLeaves bool, opt *Opt) *creator {
 
 	c := &creator{
 
@@ -83,22 +93,6 @@ func newCreator(n int, withLeaves bool, opt *Opt) *creator {
 
 		option: opt,
 
-		innerIndexes: make([]int32, 0, n),
-		innerBMs:     make([][]int32, 0, n),
-		innerSizes:   make([]int32, 0, n),
-
-		prefixIndexes:  make([]int32, 0, n),
-		prefix4BitLens: make([]uint8, 0, n),
-		prefixByteLens: make([]int32, 0, n),
-		prefixes:       make([]byte, 0, n),
-
-		leaves: make([][]byte, 0, n),
-
-		leafPrefixIndexes: make([]int32, 0, n),
-		leafPrefixLens:    make([]int32, 0, n),
-		leafPrefixes:      make([]byte, 0, n),
-		prefs:             make(map[string]int32),
-
 		innerBMCnt: make([]map[uint64]int32, maxShortSize+1),
 	}
 
@@ -197,13 +191,26 @@ func (c *creator) setLeafPrefix(nid int32, key string, keyidx int32) {
 			c.leafPrefixIndexes = append(c.leafPrefixIndexes, leafCnt-1)
 			c.leafPrefixLens = append(c.leafPrefixLens, int32(len(pref)))
 			c.leafPrefixes = append(c.leafPrefixes, pref...)
-			c.prefs[pref]++
 
 			// fmt.Printf("set for node %d %d-th leaf prefix: %q key: %s\n", nid, leafCnt-1, pref, key)
 		}
 	}
 }
 
+// setFingerprint stores the fingerprint of the last added leaf.
+func (c *creator) setFingerprint(nid int32, key string) {
+
+	must.Be.Equal(c.nodeCnt-1, nid)
+
+	n := c.option.FingerprintBits
+	if n == 0 {
+		return
+	}
+
+	leafCnt := c.nodeCnt - int32(len(c.innerIndexes))
+	c.fingerprints = setBits(c.fingerprints, (leafCnt-1)*n, n, fingerprint(key, n))
+}
+
 func (c *creator) addLeaf(nid int32, v []byte) {
 
 	must.Be.Equal(c.nodeCnt, nid)
@@ -211,7 +218,8 @@ func (c *creator) addLeaf(nid int32, v []byte) {
 	c.nodeCnt++
 
 	if c.withLeaves {
-		c.leaves = append(c.leaves, v)
+		c.leafValues = append(c.leafValues, v...)
+		c.leafValueLens = append(c.leafValueLens, int32(len(v)))
 	}
 
 }
@@ -385,25 +393,44 @@ func (c *creator) build() *Nodes {
 
 	// TODO separate leaf init
 	if c.withLeaves {
-		ns.initLeaves(c.leaves)
+		ns.initLeaves(c.leafValues, c.leafValueLens)
 	}
 
 	if *c.option.LeafPrefix {
 		ns.LeafPrefixes = &VLenArray{}
-		ns.LeafPrefixes.PresenceBM = newBM(c.leafPrefixIndexes, int32(len(c.leaves)), "r64")
+		// In filter mode there is no leaf value, the number of leaves is
+		// calculated from node count.
+		leafCnt := c.nodeCnt - innerCnt
+		ns.LeafPrefixes.PresenceBM = newBM(c.leafPrefixIndexes, leafCnt, "r64")
 		ns.LeafPrefixes.PositionBM = newBM(stepToPos(c.leafPrefixLens, 0), 0, "s32")
 		ns.LeafPrefixes.Bytes = c.leafPrefixes
 	}
 
+	if c.option.FingerprintBits > 0 {
+		ns.FingerprintBits = c.option.FingerprintBits
+		ns.Fingerprints = &Bitmap{Words: c.fingerprints}
+	}
+
+	ns.BuildOptions = newBuildOptions(c.option)
+
 	return ns
 }
 
+// newEmptyNodes creates Nodes of a SlimTrie without any key, with only the
+// options stored.
+func newEmptyNodes(opt *Opt) *Nodes {
+	return &Nodes{
+		FingerprintBits: opt.FingerprintBits,
+		BuildOptions:    newBuildOptions(opt),
+	}
+}
+
 // TODO filter mode: InnerPrefix
 func newSlimTrie(e encode.Encoder, keys []string, values interface{}, opt *Opt) (*SlimTrie, error) {
 
 	n := len(keys)
 	if n == 0 {
-		return &SlimTrie{encoder: e, nodes: &Nodes{}}, nil
+		return &SlimTrie{encoder: e, nodes: newEmptyNodes(opt)}, nil
 	}
 
 	must.Be.OK(func() {
@@ -430,7 +457,11 @@ func newSlimTrie(e encode.Encoder, keys []string, values interface{}, opt *Opt)
 
 	var tokeep []bool
 	if *opt.DedupValue {
-		tokeep = newValueToKeep(keys, vals)
+		if opt.ValueGroup != nil && values != nil {
+			tokeep = newGroupToKeep(n, values, opt.ValueGroup)
+		} else {
+			tokeep = newValueToKeep(keys, vals)
+		}
 	} else {
 		tokeep = make([]bool, n)
 		for i := 0; i < n; i++ {
@@ -438,32 +469,85 @@ func newSlimTrie(e encode.Encoder, keys []string, values interface{}, opt *Opt)
 		}
 	}
 
-	sb := sigbits.New(keys)
-	c := newCreator(n, vals != nil, opt)
+	kvs := &keySlices{
+		keys:   keys,
+		vals:   vals,
+		tokeep: tokeep,
+	}
+
+	return buildSlimTrie(e, kvs, vals != nil, opt)
+}
+
+// keyValues provides sorted keys and encoded values to create a SlimTrie from.
+type keyValues interface {
+
+	// keyCnt returns the number of keys.
+	keyCnt() int32
+
+	// key returns the i-th key.
+	key(i int32) string
+
+	// value returns the encoded value of the i-th key.
+	// It is not called in filter mode.
+	value(i int32) []byte
+
+	// keep returns false if the i-th key does not need a leaf, e.g., its value
+	// is removed by Opt.DedupValue.
+	keep(i int32) bool
+
+	// firstDiffs returns the first different bit of every two adjacent keys,
+	// the same as sigbits.FirstDiffBits() does.
+	firstDiffs() []int32
+}
+
+// keySlices is keyValues with every key and value in a separate slice.
+type keySlices struct {
+	keys   []string
+	vals   [][]byte
+	tokeep []bool
+}
+
+func (ks *keySlices) keyCnt() int32        { return int32(len(ks.keys)) }
+func (ks *keySlices) key(i int32) string   { return ks.keys[i] }
+func (ks *keySlices) value(i int32) []byte { return ks.vals[i] }
+func (ks *keySlices) keep(i int32) bool    { return ks.tokeep[i] }
+func (ks *keySlices) firstDiffs() []int32  { return sigbits.FirstDiffBits(ks.keys) }
+
+// buildSlimTrie creates a SlimTrie from sorted keys and encoded values.
+// withValues is false in filter mode.
+func buildSlimTrie(e encode.Encoder, kvs keyValues, withValues bool, opt *Opt) (*SlimTrie, error) {
+
+	n := kvs.keyCnt()
 
-	queue := make([]subset, 0, n*2)
-	queue = append(queue, subset{0, int32(n), 0})
+	diffs := kvs.firstDiffs()
+	c := newCreator(withValues, opt)
 
-	for i := 0; i < len(queue); i++ {
-		nid := int32(i)
-		o := queue[i]
+	// queue is a FIFO of subsets to create node for, in BFS order.
+	// Processed subsets are removed from the head so that memory is released
+	// when the queue grows.
+	queue := []subset{{0, n, 0}}
+
+	for nid := int32(0); len(queue) > 0; nid++ {
+		o := queue[0]
+		queue = queue[1:]
 		s, e := o.keyStart, o.keyEnd
 
 		// single key, it is a leaf
 		if e-s == 1 {
-			must.Be.True(tokeep[s])
-			if vals == nil {
-				c.addLeaf(nid, nil)
+			must.Be.True(kvs.keep(s))
+			if withValues {
+				c.addLeaf(nid, kvs.value(s))
 			} else {
-				c.addLeaf(nid, vals[s])
+				c.addLeaf(nid, nil)
 			}
-			c.setLeafPrefix(nid, keys[s], o.fromKeyBit)
+			c.setLeafPrefix(nid, kvs.key(s), o.fromKeyBit)
+			c.setFingerprint(nid, kvs.key(s))
 			continue
 		}
 
 		// create an inner node
 
-		wordStart, prefCounts := sb.CountPrefixes(s, e, maxWordSize)
+		wordStart, prefCounts := countPrefixes(diffs[s:e-1], maxWordSize)
 		_ = prefCounts
 
 		var wordsize int32
@@ -506,16 +590,21 @@ func newSlimTrie(e encode.Encoder, keys []string, values interface{}, opt *Opt)
 			panic("wordStart smaller than o.fromKeyBit")
 		}
 
-		ks := make([]string, 0)
+		// A label is a word with 0, 4 or 8 bits.
+		// A path is an encoded representation of both the length and the bits.
+		//
+		// Same as bmtree.PathsOf() but without a copy of keys to keep.
+		labelPaths := make([]uint64, 0)
+		prevPath := ^uint64(0)
 		for i := s; i < e; i++ {
-			if tokeep[i] {
-				ks = append(ks, keys[i])
+			if kvs.keep(i) {
+				p := bmtree.PathOf(kvs.key(i), wordStart, wordsize)
+				if p != prevPath {
+					labelPaths = append(labelPaths, p)
+				}
+				prevPath = p
 			}
 		}
-
-		// A label is a word with 0, 4 or 8 bits.
-		// A path is an encoded representation of both the length and the bits.
-		labelPaths := bmtree.PathsOf(ks, wordStart, wordsize, true)
 		must.Be.True(len(labelPaths) > 0)
 
 		// Without the bits of label word at parent node
@@ -525,7 +614,7 @@ func newSlimTrie(e encode.Encoder, keys []string, values interface{}, opt *Opt)
 		for i, p := range labelPaths {
 			idxs[i] = bmtree.PathToIndex(bitmapSize, p)
 		}
-		c.addInner(nid, idxs, bitmapSize, step, keys[s], o.fromKeyBit)
+		c.addInner(nid, idxs, bitmapSize, step, kvs.key(s), o.fromKeyBit)
 
 		// put keys with the same starting word to queue.
 
@@ -533,7 +622,7 @@ func newSlimTrie(e encode.Encoder, keys []string, values interface{}, opt *Opt)
 
 			// Find the first key starting with label
 			for ; s < e; s++ {
-				kpath := bmtree.PathOf(keys[s], wordStart, wordsize)
+				kpath := bmtree.PathOf(kvs.key(s), wordStart, wordsize)
 				if kpath == pth {
 					break
 				}
@@ -542,7 +631,7 @@ func newSlimTrie(e encode.Encoder, keys []string, values interface{}, opt *Opt)
 			// Continue looking for the first key not starting with label
 			var j int32
 			for j = s + 1; j < e; j++ {
-				kpath := bmtree.PathOf(keys[j], wordStart, wordsize)
+				kpath := bmtree.PathOf(kvs.key(j), wordStart, wordsize)
 				if kpath != pth {
 					break
 				}
@@ -567,6 +656,39 @@ func newSlimTrie(e encode.Encoder, keys []string, values interface{}, opt *Opt)
 	}, nil
 }
 
+// countPrefixes is the same as sigbits.SigBits.CountPrefixes() but works on
+// the first different bits of keys instead of keys.
+//
+// It returns the minimal bit index where there is a different bit and a
+// []int32 of length maxitem, the i-th element is the number of distinct i-bit
+// words.
+func countPrefixes(firstdiffs []int32, maxitem int32) (int32, []int32) {
+
+	min := int32(0x7fffffff)
+	for _, d := range firstdiffs {
+		if min > d {
+			min = d
+		}
+	}
+
+	// counts[i] is number of i-th bits that is the first diff bit.
+	counts := make([]int32, maxitem-1)
+	for _, d := range firstdiffs {
+		d -= min
+		if d < maxitem-1 {
+			counts[d]++
+		}
+	}
+
+	rst := make([]int32, maxitem)
+	rst[0] = 1
+	for i := int32(0); i < maxitem-1; i++ {
+		rst[i+1] = rst[i] + counts[i]
+	}
+
+	return min, rst
+}
+
 func encodeValues(n int, values interface{}, e encode.Encoder) [][]byte {
 	if values == nil {
 		return nil
@@ -615,6 +737,23 @@ func newValueToKeep(keys []string, values [][]byte) []bool {
 	return tokeep
 }
 
+// newGroupToKeep is similar to newValueToKeep except that the value of
+// key[i+1] is considered the same as key[i] if they are in the same group.
+func newGroupToKeep(n int, values interface{}, group func(interface{}) interface{}) []bool {
+
+	tokeep := make([]bool, n)
+	rvals := reflect.ValueOf(values)
+
+	var prev interface{}
+	for i := 0; i < n; i++ {
+		g := group(getV(rvals, int32(i)))
+		tokeep[i] = i == 0 || g != prev
+		prev = g
+	}
+
+	return tokeep
+}
+
 func getV(reflectSlice reflect.Value, i int32) interface{} {
 	if reflectSlice.IsNil() {
 		return nil
@@ -622,20 +761,38 @@ func getV(reflectSlice reflect.Value, i int32) interface{} {
 	return reflectSlice.Index(int(i)).Interface()
 }
 
-func (ns *Nodes) initLeaves(elts [][]byte) {
-
-	n := len(elts)
-	sz := 0
-	for _, elt := range elts {
-		sz += len(elt)
+// initLeaves stores encoded values of leaves.
+//
+// If all values have the same size, the size is stored in FixedSize.
+// Otherwise the starting position of every value is stored in PositionBM.
+// A value may be empty, thus the i-th position is shifted by i to make
+// positions distinct.
+func (ns *Nodes) initLeaves(values []byte, lens []int32) {
+
+	n := len(lens)
+	fixed := true
+	for _, l := range lens {
+		fixed = fixed && l == lens[0]
 	}
 
-	lb := make([]byte, 0, sz)
-	for i := 0; i < n; i++ {
-		lb = append(lb, elts[i]...)
-	}
+	// values may have extra capacity, make a copy of exact size.
+	lb := make([]byte, len(values))
+	copy(lb, values)
+
 	ns.Leaves = &VLenArray{}
 	ns.Leaves.Bytes = lb
+
+	if fixed {
+		if n > 0 {
+			ns.Leaves.FixedSize = lens[0]
+		}
+	} else {
+		ps := stepToPos(lens, 0)
+		for i := range ps {
+			ps[i] += int32(i)
+		}
+		ns.Leaves.PositionBM = newBM(ps, 0, "s32")
+	}
 }
 
 func stepToPos(steps []int32, shift int32) []int32 {
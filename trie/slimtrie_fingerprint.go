@@ -0,0 +1,64 @@
+package trie
+
+// matchFingerprint checks if the fingerprint of key equals the one stored in
+// leaf "nodeid".
+func (st *SlimTrie) matchFingerprint(nodeid int32, key string) bool {
+	ns := st.nodes
+	n := ns.FingerprintBits
+	ith, _ := st.getLeafIndex(nodeid)
+	return getBits(ns.Fingerprints.Words, ith*n, n) == fingerprint(key, n)
+}
+
+// fingerprint returns the n-bit hash of key, 0 < n <= 64.
+//
+// It is FNV-1a followed by the finalizer of murmur3 to mix all bits.
+func fingerprint(key string, n int32) uint64 {
+
+	h := uint64(14695981039346656037)
+	for i := 0; i < len(key); i++ {
+		h ^= uint64(key[i])
+		h *= 1099511628211
+	}
+
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+
+	return h >> uint(64-n)
+}
+
+// setBits sets n bits starting at bit "pos" to v and returns the updated
+// words, which grows if needed.
+func setBits(words []uint64, pos, n int32, v uint64) []uint64 {
+
+	for int32(len(words)) < (pos+n+63)>>6 {
+		words = append(words, 0)
+	}
+
+	w := pos >> 6
+	off := uint(pos & 63)
+
+	words[w] |= v << off
+	if off+uint(n) > 64 {
+		words[w+1] |= v >> (64 - off)
+	}
+	return words
+}
+
+// getBits returns n bits starting at bit "pos".
+func getBits(words []uint64, pos, n int32) uint64 {
+
+	w := pos >> 6
+	off := uint(pos & 63)
+
+	v := words[w] >> off
+	if off+uint(n) > 64 {
+		v |= words[w+1] << (64 - off)
+	}
+	if n < 64 {
+		v &= (uint64(1) << uint(n)) - 1
+	}
+	return v
+}
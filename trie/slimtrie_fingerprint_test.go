@@ -0,0 +1,116 @@
+package trie
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_FingerprintBits(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+	absent := makeAbsentKeys(keys, len(keys)*5, 0, 20)
+
+	for _, opt := range []Opt{{}, {InnerPrefix: Bool(true)}, {LeafPrefix: Bool(true)}} {
+
+		for _, n := range []int32{1, 4, 8, 13, 64} {
+
+			opt.FingerprintBits = n
+
+			st, err := NewSlimTrie(encode.I32{}, keys, values, opt)
+			ta.NoError(err)
+			ta.Equal(n, st.nodes.FingerprintBits)
+
+			testPresentKeysGet(t, st, keys, values)
+
+			fp := 0
+			for _, k := range absent {
+				if st.GetID(k) != -1 {
+					fp++
+				}
+			}
+
+			rate := float64(fp) / float64(len(absent))
+			want := 1.0 / float64(uint64(1)<<uint(n))
+
+			ta.True(rate <= want*1.5, "opt: %+v, false positive rate: %f, want <= %f", opt, rate, want*1.5)
+		}
+	}
+}
+
+func TestSlimTrie_FingerprintBits_normalize(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := []string{"a", "b", "c"}
+	values := makeI32s(len(keys))
+
+	cases := []struct {
+		opt  Opt
+		want int32
+	}{
+		{Opt{FingerprintBits: -1}, 0},
+		{Opt{FingerprintBits: 100}, 64},
+		{Opt{FingerprintBits: 8, Complete: Bool(true)}, 0},
+	}
+
+	for i, c := range cases {
+		st, err := NewSlimTrie(encode.I32{}, keys, values, c.opt)
+		ta.NoError(err)
+		ta.Equal(c.want, st.nodes.FingerprintBits, "%d-th: case: %+v", i+1, c)
+
+		testPresentKeysGet(t, st, keys, values)
+	}
+}
+
+func TestSlimTrie_FingerprintBits_marshal(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+
+	st1, err := NewSlimTrie(encode.I32{}, keys, values, Opt{FingerprintBits: 12})
+	ta.NoError(err)
+
+	buf, err := st1.Marshal()
+	ta.NoError(err)
+
+	st2, err := NewSlimTrie(encode.I32{}, nil, nil)
+	ta.NoError(err)
+	ta.NoError(st2.Unmarshal(buf))
+
+	slimtrieEqual(st1, st2, t)
+	testPresentKeysGet(t, st2, keys, values)
+}
+
+func TestSetGetBits(t *testing.T) {
+
+	ta := require.New(t)
+
+	for _, n := range []int32{1, 3, 7, 8, 31, 63, 64} {
+
+		cnt := int32(200)
+		vals := make([]uint64, cnt)
+
+		var words []uint64
+		for i := int32(0); i < cnt; i++ {
+			vals[i] = rand.Uint64()
+			if n < 64 {
+				vals[i] &= (uint64(1) << uint(n)) - 1
+			}
+			words = setBits(words, i*n, n, vals[i])
+		}
+
+		ta.Equal(int((cnt*n+63)/64), len(words))
+
+		for i := int32(0); i < cnt; i++ {
+			ta.Equal(vals[i], getBits(words, i*n, n), "n: %d, i: %d", n, i)
+		}
+	}
+}
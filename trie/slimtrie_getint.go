@@ -80,3 +80,103 @@ func (st *SlimTrie) GetI64(key string) (int64, bool) {
 
 	return v, true
 }
+
+// GetU8 is same as Get() except it is optimized for uint8.
+//
+// Since 0.5.11
+func (st *SlimTrie) GetU8(key string) (uint8, bool) {
+
+	eqID := st.GetID(key)
+
+	if eqID == -1 {
+		return 0, false
+	}
+
+	ith, _ := st.getLeafIndex(eqID)
+
+	v := st.nodes.Leaves.Bytes[ith]
+
+	return v, true
+}
+
+// GetU16 is same as Get() except it is optimized for uint16.
+//
+// Since 0.5.11
+func (st *SlimTrie) GetU16(key string) (uint16, bool) {
+
+	eqID := st.GetID(key)
+
+	if eqID == -1 {
+		return 0, false
+	}
+
+	ith, _ := st.getLeafIndex(eqID)
+	stIdx := ith << 1
+
+	b := st.nodes.Leaves.Bytes[stIdx : stIdx+2]
+
+	v := uint16(b[0]) | uint16(b[1])<<8
+
+	return v, true
+}
+
+// GetU32 is same as Get() except it is optimized for uint32.
+//
+// Since 0.5.11
+func (st *SlimTrie) GetU32(key string) (uint32, bool) {
+
+	eqID := st.GetID(key)
+
+	if eqID == -1 {
+		return 0, false
+	}
+
+	ith, _ := st.getLeafIndex(eqID)
+	stIdx := ith << 2
+
+	b := st.nodes.Leaves.Bytes[stIdx : stIdx+4]
+
+	v := uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16 | uint32(b[3])<<24
+
+	return v, true
+}
+
+// GetU64 is same as Get() except it is optimized for uint64.
+//
+// Since 0.5.11
+func (st *SlimTrie) GetU64(key string) (uint64, bool) {
+
+	eqID := st.GetID(key)
+
+	if eqID == -1 {
+		return 0, false
+	}
+
+	ith, _ := st.getLeafIndex(eqID)
+	stIdx := ith << 3
+
+	b := st.nodes.Leaves.Bytes[stIdx : stIdx+8]
+
+	v := uint64(b[0]) | uint64(b[1])<<8 | uint64(b[2])<<16 | uint64(b[3])<<24 | uint64(b[4])<<32 | uint64(b[5])<<40 | uint64(b[6])<<48 | uint64(b[7])<<56
+
+	return v, true
+}
+
+// GetValueBytes is same as Get() except it returns the encoded value.
+// The returned slice references the internal storage of SlimTrie and must not
+// be modified.
+// The value bytes is nil if SlimTrie is created without values.
+//
+// Since 0.5.11
+func (st *SlimTrie) GetValueBytes(key string) ([]byte, bool) {
+
+	eqID := st.GetID(key)
+
+	if eqID == -1 {
+		return nil, false
+	}
+
+	ith, _ := st.getLeafIndex(eqID)
+
+	return st.getIthLeafBytes(ith), true
+}
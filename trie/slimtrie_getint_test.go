@@ -110,3 +110,139 @@ func TestSlimTrie_GetI64(t *testing.T) {
 		ta.Equal(values[i], v, "Get:%v", key)
 	}
 }
+
+func TestSlimTrie_GetU8(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	values := make([]uint8, len(keys))
+	for i := 0; i < len(keys); i++ {
+		values[i] = uint8(fibhash64(uint64(i)))
+	}
+
+	st, err := NewSlimTrie(encode.U8{}, keys, values)
+	ta.NoError(err)
+
+	testUnknownKeysGRS(t, st, randVStrings(len(keys)*5, 0, 10))
+
+	for i, key := range keys {
+
+		dd("test Get: present: %s", key)
+
+		v, found := st.GetU8(key)
+		ta.True(found, "Get:%v", key)
+		ta.Equal(values[i], v, "Get:%v", key)
+	}
+}
+
+func TestSlimTrie_GetU16(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	values := make([]uint16, len(keys))
+	for i := 0; i < len(keys); i++ {
+		values[i] = uint16(fibhash64(uint64(i)))
+	}
+
+	st, err := NewSlimTrie(encode.U16{}, keys, values)
+	ta.NoError(err)
+
+	testUnknownKeysGRS(t, st, randVStrings(len(keys)*5, 0, 10))
+
+	for i, key := range keys {
+
+		dd("test Get: present: %s", key)
+
+		v, found := st.GetU16(key)
+		ta.True(found, "Get:%v", key)
+		ta.Equal(values[i], v, "Get:%v", key)
+	}
+}
+
+func TestSlimTrie_GetU32(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	values := make([]uint32, len(keys))
+	for i := 0; i < len(keys); i++ {
+		values[i] = uint32(fibhash64(uint64(i)))
+	}
+
+	st, err := NewSlimTrie(encode.U32{}, keys, values)
+	ta.NoError(err)
+
+	testUnknownKeysGRS(t, st, randVStrings(len(keys)*5, 0, 10))
+
+	for i, key := range keys {
+
+		dd("test Get: present: %s", key)
+
+		v, found := st.GetU32(key)
+		ta.True(found, "Get:%v", key)
+		ta.Equal(values[i], v, "Get:%v", key)
+	}
+}
+
+func TestSlimTrie_GetU64(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	values := make([]uint64, len(keys))
+	for i := 0; i < len(keys); i++ {
+		values[i] = uint64(fibhash64(uint64(i)))
+	}
+
+	st, err := NewSlimTrie(encode.U64{}, keys, values)
+	ta.NoError(err)
+
+	testUnknownKeysGRS(t, st, randVStrings(len(keys)*5, 0, 10))
+
+	for i, key := range keys {
+
+		dd("test Get: present: %s", key)
+
+		v, found := st.GetU64(key)
+		ta.True(found, "Get:%v", key)
+		ta.Equal(values[i], v, "Get:%v", key)
+	}
+}
+
+func TestSlimTrie_GetValueBytes(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	values := make([]uint64, len(keys))
+	for i := 0; i < len(keys); i++ {
+		values[i] = fibhash64(uint64(i))
+	}
+
+	st, err := NewSlimTrie(encode.U64{}, keys, values)
+	ta.NoError(err)
+
+	for i, key := range keys {
+		b, found := st.GetValueBytes(key)
+		ta.True(found, "Get:%v", key)
+		ta.Equal(encode.U64{}.Encode(values[i]), b, "Get:%v", key)
+	}
+
+	allocs := testing.AllocsPerRun(10, func() {
+		for _, key := range keys[:1000] {
+			st.GetValueBytes(key)
+		}
+	})
+	ta.Equal(float64(0), allocs)
+
+	// no value stored
+
+	st, err = NewSlimTrie(nil, keys, nil)
+	ta.NoError(err)
+
+	b, found := st.GetValueBytes(keys[0])
+	ta.True(found)
+	ta.Nil(b)
+}
@@ -0,0 +1,98 @@
+package trie
+
+// GetManyID looks up a batch of keys and stores the leaf node id of keys[i] in
+// ids[i], or -1 if keys[i] is not found.
+// "ids" must have at least len(keys) elements.
+//
+// It works with keys in any order, but it is optimized for sorted keys:
+// A walk from root to a leaf is resumed from the deepest node shared with the
+// previous key, instead of from root.
+// Thus the shared upper part of path, especially the big inner nodes, is not
+// walked again.
+//
+// Since 0.5.11
+func (st *SlimTrie) GetManyID(keys []string, ids []int32) {
+	st.getManyID(keys, ids, make([]walkStep, 0, 16))
+}
+
+// getManyID is the implementation of GetManyID.
+// "path" is a buffer to store walked nodes, it is returned for reuse.
+func (st *SlimTrie) getManyID(keys []string, ids []int32, path []walkStep) []walkStep {
+
+	if st.nodes.NodeTypeBM == nil {
+		for i := range keys {
+			ids[i] = -1
+		}
+		return path
+	}
+
+	qr := &querySession{}
+	path = path[:0]
+	prev := ""
+
+	for i, key := range keys {
+
+		path = reusablePath(path, commonPrefixLen(prev, key)<<3)
+
+		start := walkStep{0, 0}
+		if len(path) > 0 {
+			start = path[len(path)-1]
+			path = path[:len(path)-1]
+		}
+
+		ids[i] = st.getID(key, qr, start.nodeID, start.keyBit, &path)
+		prev = key
+	}
+
+	return path
+}
+
+// GetMany looks up a batch of keys and stores the value of keys[i] in
+// values[i], or nil if keys[i] is not found.
+// "values" must have at least len(keys) elements.
+//
+// To tell a nil value from an absent key, use GetManyID().
+//
+// See GetManyID.
+//
+// Since 0.5.11
+func (st *SlimTrie) GetMany(keys []string, values []interface{}) {
+
+	ids := make([]int32, len(keys))
+	st.GetManyID(keys, ids)
+
+	for i, id := range ids {
+		if id == -1 {
+			values[i] = nil
+		} else {
+			values[i] = st.getLeaf(id)
+		}
+	}
+}
+
+// reusablePath removes nodes from path that can not be reused by a key sharing
+// the first "sharedBits" bits with the previous key.
+//
+// The walk to a node that starts at the i-th bit depends only on the bits
+// before i.
+// Thus a node can be reused if it starts at or before "sharedBits".
+func reusablePath(path []walkStep, sharedBits int32) []walkStep {
+	n := len(path)
+	for n > 0 && path[n-1].keyBit > sharedBits {
+		n--
+	}
+	return path[:n]
+}
+
+// commonPrefixLen returns the length in byte of the common prefix of a and b.
+func commonPrefixLen(a, b string) int32 {
+	n := len(a)
+	if n > len(b) {
+		n = len(b)
+	}
+
+	i := 0
+	for ; i < n && a[i] == b[i]; i++ {
+	}
+	return int32(i)
+}
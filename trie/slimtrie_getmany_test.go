@@ -0,0 +1,102 @@
+package trie
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/openacid/slim/encode"
+	"github.com/openacid/testkeys"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_GetManyID(t *testing.T) {
+
+	ta := require.New(t)
+
+	opts := []Opt{
+		{},
+		{InnerPrefix: Bool(true)},
+		{Complete: Bool(true)},
+	}
+
+	for _, typ := range testkeys.AssetNames() {
+
+		keys := getKeys(typ)
+		if len(keys) >= 1000 {
+			continue
+		}
+
+		values := makeI32s(len(keys))
+
+		queries := append(makeAbsentKeys(keys, len(keys)*2, 0, 20), keys...)
+		sort.Strings(queries)
+
+		shuffled := append([]string{}, queries...)
+		rand.Shuffle(len(shuffled), func(i, j int) {
+			shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+		})
+
+		for _, opt := range opts {
+
+			st, err := NewSlimTrie(encode.I32{}, keys, values, opt)
+			ta.NoError(err)
+
+			for _, qs := range [][]string{queries, shuffled} {
+
+				ids := make([]int32, len(qs))
+				st.GetManyID(qs, ids)
+
+				vals := make([]interface{}, len(qs))
+				st.GetMany(qs, vals)
+
+				for i, key := range qs {
+					ta.Equal(st.GetID(key), ids[i], "keys: %s, opt: %+v, key: %q", typ, opt, key)
+
+					v, _ := st.Get(key)
+					ta.Equal(v, vals[i], "keys: %s, opt: %+v, key: %q", typ, opt, key)
+				}
+			}
+		}
+	}
+}
+
+func TestSlimTrie_GetMany_empty(t *testing.T) {
+
+	ta := require.New(t)
+
+	st, err := NewSlimTrie(encode.I32{}, []string{}, []int32{})
+	ta.NoError(err)
+
+	qs := []string{"", "a", "b"}
+
+	ids := make([]int32, len(qs))
+	st.GetManyID(qs, ids)
+	ta.Equal([]int32{-1, -1, -1}, ids)
+
+	vals := make([]interface{}, len(qs))
+	st.GetMany(qs, vals)
+	ta.Equal([]interface{}{nil, nil, nil}, vals)
+}
+
+func TestCommonPrefixLen(t *testing.T) {
+
+	ta := require.New(t)
+
+	cases := []struct {
+		a, b string
+		want int32
+	}{
+		{"", "", 0},
+		{"a", "", 0},
+		{"", "a", 0},
+		{"a", "a", 1},
+		{"ab", "ac", 1},
+		{"abc", "ab", 2},
+		{"xb", "ab", 0},
+	}
+
+	for i, c := range cases {
+		ta.Equal(c.want, commonPrefixLen(c.a, c.b), "%d-th: case: %+v", i+1, c)
+	}
+}
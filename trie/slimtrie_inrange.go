@@ -0,0 +1,261 @@
+package trie
+
+// keyRange is a key range ["from", "to") for checking if a path in a SlimTrie
+// may lead to a key in it.
+//
+// A path is matched against the range bit by bit.
+// The state of a partially matched path is whether it is still equal to the
+// prefix of "from" and whether it is still equal to the prefix of "to".
+// All possible states of paths are tracked as a set in a uint8, in which bit
+// "lo | hi<<1" is set if there is a path in state (lo, hi).
+//
+// State (false, false) means any key with this prefix is in range.
+type keyRange struct {
+	from, to string
+
+	fromBitLen, toBitLen int32
+
+	// unbounded is true if "to" is empty.
+	unbounded bool
+}
+
+const (
+	// flags of a state
+	rangeLo = uint8(1)
+	rangeHi = uint8(2)
+
+	// rangeIn is the set of the only state (false, false).
+	rangeIn = uint8(1)
+
+	// rangeCanEnd is the set of states a path is allowed to end in: a path
+	// still equal to the prefix of "from" is less than "from".
+	rangeCanEnd = uint8(1<<0 | 1<<rangeHi)
+)
+
+func newKeyRange(from, to string) *keyRange {
+	return &keyRange{
+		from:       from,
+		to:         to,
+		fromBitLen: int32(len(from)) << 3,
+		toBitLen:   int32(len(to)) << 3,
+		unbounded:  to == "",
+	}
+}
+
+// start returns the states of the empty path.
+func (r *keyRange) start() uint8 {
+	st := rangeLo
+	if !r.unbounded {
+		st |= rangeHi
+	}
+	return r.normalize(1<<st, 0)
+}
+
+// normalize updates states when a path reaches bit position "i".
+// A path passes "from" when "from" ends; a path equal to "to" when "to" ends
+// is out of range.
+func (r *keyRange) normalize(states uint8, i int32) uint8 {
+
+	var rst uint8
+
+	for st := uint8(0); st < 4; st++ {
+		if states&(1<<st) == 0 {
+			continue
+		}
+
+		s := st
+		if s&rangeLo != 0 && i >= r.fromBitLen {
+			s &^= rangeLo
+		}
+		if s&rangeHi != 0 && i >= r.toBitLen {
+			continue
+		}
+		rst |= 1 << s
+	}
+	return rst
+}
+
+// fix appends bit "b" at position "i" to paths and returns the new states.
+func (r *keyRange) fix(states uint8, i int32, b byte) uint8 {
+
+	var rst uint8
+
+	for st := uint8(0); st < 4; st++ {
+		if states&(1<<st) == 0 {
+			continue
+		}
+
+		s := st
+		if s&rangeLo != 0 {
+			fb := bitAt(r.from, i)
+			if b < fb {
+				continue
+			}
+			if b > fb {
+				s &^= rangeLo
+			}
+		}
+
+		if s&rangeHi != 0 {
+			tb := bitAt(r.to, i)
+			if b > tb {
+				continue
+			}
+			if b < tb {
+				s &^= rangeHi
+			}
+		}
+		rst |= 1 << s
+	}
+
+	return r.normalize(rst, i+1)
+}
+
+// free appends an unknown bit at position "i" to paths.
+func (r *keyRange) free(states uint8, i int32) uint8 {
+	return r.fix(states, i, 0) | r.fix(states, i, 1)
+}
+
+// fixBytes appends bits in "bs" from bit "i" to bit "end" to paths.
+// The first bit of "bs" is at position "i&^7".
+func (r *keyRange) fixBytes(states uint8, bs []byte, i, end int32) uint8 {
+
+	base := i &^ 7
+
+	for ; i < end && states != 0 && states&rangeIn == 0; i++ {
+		j := i - base
+		states = r.fix(states, i, bs[j>>3]>>uint(7-j&7)&1)
+	}
+	return states
+}
+
+// bitAt returns the i-th bit of s, counting from the most significant bit of
+// the first byte.
+func bitAt(s string, i int32) byte {
+	return s[i>>3] >> uint(7-i&7) & 1
+}
+
+// MayHaveKeyInRange checks if there may be a key in ["from", "to").
+// An empty "to" means there is no upper bound.
+//
+// If SlimTrie is created with Opt.Complete, the result is exact.
+// Otherwise the skipped bits and the removed suffixes of keys are unknown,
+// and it may return true for a range without key, but never returns false for
+// a range with a key.
+// A key removed by Opt.DedupValue is not stored thus is not considered.
+//
+// E.g., to skip an SSTable without key in a range:
+//
+//	if !st.MayHaveKeyInRange("abc", "abd") {
+//		// no key starts with "abc"
+//	}
+//
+// Since 0.5.11
+func (st *SlimTrie) MayHaveKeyInRange(from, to string) bool {
+
+	if st.nodes.NodeTypeBM == nil {
+		return false
+	}
+
+	if to != "" && from >= to {
+		return false
+	}
+
+	r := newKeyRange(from, to)
+	return st.mayHaveInNode(r, 0, 0, r.start())
+}
+
+// mayHaveInNode checks if the sub-trie at "nodeid" may have a key in range.
+// "i" is the bit position in key where the node starts and "states" are the
+// states of the path to it.
+func (st *SlimTrie) mayHaveInNode(r *keyRange, nodeid int32, i int32, states uint8) bool {
+
+	if states == 0 {
+		return false
+	}
+	if states&rangeIn != 0 {
+		return true
+	}
+
+	qr := &querySession{}
+	st.getInner(nodeid, qr)
+
+	if !qr.isInner {
+
+		if st.nodes.LeafPrefixes == nil {
+			// the rest of the key is unknown
+			return true
+		}
+
+		if !qr.hasLeafPrefix {
+			return states&rangeCanEnd != 0
+		}
+
+		end := i&^7 + int32(len(qr.leafPrefix))<<3
+		states = r.fixBytes(states, qr.leafPrefix, i, end)
+		if states&rangeIn != 0 {
+			return true
+		}
+		return states&rangeCanEnd != 0
+	}
+
+	if qr.hasPrefixContent {
+		end := i&^7 + qr.prefixLen
+		states = r.fixBytes(states, qr.prefix[1:], i, end)
+		i = end
+	} else {
+		end := i + qr.prefixLen
+		for ; i < end && states != 0 && states&rangeIn == 0; i++ {
+			states = r.free(states, i)
+		}
+		i = end
+	}
+
+	if states == 0 {
+		return false
+	}
+	if states&rangeIn != 0 {
+		return true
+	}
+
+	f := &iterFrame{
+		from:     qr.from,
+		isShort:  qr.to-qr.from == st.nodes.ShortSize,
+		bm:       qr.bm,
+		labelBit: 0,
+	}
+
+	size := innerSize
+	if qr.wordSize == bigWordSize {
+		size = bigInnerSize
+	}
+
+	child, _ := st.getChildRange(qr)
+
+	for ; f.labelBit < size; f.labelBit++ {
+
+		if !st.hasLabelBit(f) {
+			continue
+		}
+
+		if f.labelBit == 0 {
+			// the empty label: a key ends at this node.
+			if states&rangeCanEnd != 0 {
+				return true
+			}
+		} else {
+			label := f.labelBit - 1
+			s := states
+			for j := int32(0); j < qr.wordSize; j++ {
+				s = r.fix(s, i+j, byte(label>>uint(qr.wordSize-1-j)&1))
+			}
+
+			if st.mayHaveInNode(r, child, i+qr.wordSize, s) {
+				return true
+			}
+		}
+		child++
+	}
+
+	return false
+}
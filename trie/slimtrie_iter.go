@@ -0,0 +1,507 @@
+package trie
+
+import "github.com/openacid/low/bitmap"
+
+// Iterator walks through leaves of a SlimTrie in key order.
+//
+// Since 0.5.11
+type Iterator struct {
+	st *SlimTrie
+	qr *querySession
+
+	// inner nodes from root to the parent of current leaf.
+	frames []iterFrame
+
+	// current leaf node id. -1 means there is no more leaf.
+	nodeID int32
+
+	// the first leaf not to visit. -1 means no upper bound.
+	endID int32
+
+	// the last leaf to visit. -1 means no upper bound.
+	lastID int32
+
+	started bool
+
+	// withKey is true if complete key content is stored in SlimTrie and key
+	// is rebuilt along the walk.
+	withKey bool
+	key     []byte
+
+	// buffers of paths to the first and the end leaf.
+	path, endPath []int32
+}
+
+// iterFrame is an inner node on the path to current leaf.
+type iterFrame struct {
+	// next is the next child id to visit, end is the last child id plus 1.
+	next, end int32
+
+	// the bit position in key where the label of a child starts.
+	labelPos int32
+	wordSize int32
+
+	// bit range in Inners and the 17-bit bitmap of a short node.
+	from, to int32
+	isShort  bool
+	bm       uint64
+
+	// index of the label bit of the last visited child.
+	labelBit int32
+}
+
+// Iter returns an Iterator that yields leaves in key order, starting from the
+// first key >= "from", ending before the first key >= "to".
+// An empty "to" means there is no upper bound.
+//
+// If SlimTrie is created with Opt.Complete, bounds are exact and keys are
+// rebuilt and available with Iterator.Key().
+//
+// Otherwise bits of keys that are not stored are unknown, and bounds are
+// widened so that no key in ["from", "to") is missed.
+// Thus there may be false positive: a leaf out of ["from", "to") may be
+// yielded.
+//
+//	it := st.Iter("abc", "abd")
+//	for it.Next() {
+//		fmt.Println(it.Key(), it.Value())
+//	}
+//
+// Since 0.5.11
+func (st *SlimTrie) Iter(from, to string) *Iterator {
+
+	it := &Iterator{
+		st: st,
+		qr: &querySession{},
+	}
+
+	it.reset(from, to)
+	return it
+}
+
+// reset positions the Iterator before the first leaf in range ["from", "to").
+// Buffers of the Iterator are reused.
+func (it *Iterator) reset(from, to string) {
+
+	st := it.st
+	it.clear()
+
+	if st.nodes.NodeTypeBM == nil {
+		return
+	}
+
+	startID := st.iterStart(from, it.withKey)
+	if startID == -1 {
+		return
+	}
+
+	if to != "" {
+		it.endID = st.iterEnd(to, it.withKey)
+	}
+
+	it.path = st.appendPath(it.path[:0], startID)
+
+	if it.endID != -1 {
+		it.endPath = st.appendPath(it.endPath[:0], it.endID)
+		if !st.pathLess(it.path, it.endPath) {
+			return
+		}
+	}
+
+	it.seek(it.path)
+}
+
+// resetPrefix positions the Iterator before the first leaf with "prefix".
+func (it *Iterator) resetPrefix(prefix string) {
+
+	it.clear()
+
+	firstID, lastID := it.st.PrefixRange(prefix)
+	if firstID == -1 {
+		return
+	}
+
+	it.lastID = lastID
+	it.path = it.st.appendPath(it.path[:0], firstID)
+	it.seek(it.path)
+}
+
+// clear removes all states and leaves an Iterator with no leaf to visit.
+func (it *Iterator) clear() {
+	it.frames = it.frames[:0]
+	it.key = it.key[:0]
+	it.nodeID = -1
+	it.endID = -1
+	it.lastID = -1
+	it.started = false
+	it.withKey = it.st.isComplete()
+}
+
+// iterStart returns the first leaf to visit for a lower bound "from", or -1
+// if there is none.
+//
+// If SlimTrie is not complete, the bits skipped by a node without stored
+// prefix are unknown. All keys below such a node may be greater or less than
+// "from", thus it starts from the left most leaf of the top-most such node.
+func (st *SlimTrie) iterStart(from string, exact bool) int32 {
+
+	_, eqID, rID := st.searchID(from)
+
+	if !exact {
+		if nid, _ := st.unknownAncestor(from); nid != -1 {
+			return st.leftMost(nid)
+		}
+	}
+
+	if eqID != -1 {
+		return eqID
+	}
+	return rID
+}
+
+// iterEnd returns the first leaf not to visit for an upper bound "to", or -1
+// if there is no upper bound.
+//
+// If SlimTrie is not complete, it ends after the top-most node with unknown
+// skipped bits, for the same reason as iterStart.
+// And a leaf without leaf prefix matching "to" may have a key less than "to",
+// thus it ends after that leaf.
+func (st *SlimTrie) iterEnd(to string, exact bool) int32 {
+
+	_, eqID, rID := st.searchID(to)
+
+	if !exact {
+		if nid, nextID := st.unknownAncestor(to); nid != -1 {
+			return nextID
+		}
+
+		if eqID != -1 && st.nodes.LeafPrefixes == nil {
+			return rID
+		}
+	}
+
+	if eqID != -1 {
+		return eqID
+	}
+	return rID
+}
+
+// unknownAncestor returns the top-most inner node on the path of key, that
+// has skipped bits but does not store them, and the first leaf after all
+// leaves below it.
+// It returns -1, -1 if there is no such node, or the path of key leaves the
+// trie before reaching such a node.
+// The second return value is -1 if there is no leaf after the node.
+func (st *SlimTrie) unknownAncestor(key string) (int32, int32) {
+
+	l := int32(8 * len(key))
+	qr := &querySession{
+		keyBitLen: l,
+		key:       key,
+	}
+
+	nid, i, nextID := int32(0), int32(0), int32(-1)
+
+	for {
+
+		qr.isInner = false
+		qr.prefixLen = 0
+		qr.hasPrefixContent = false
+
+		st.getInner(nid, qr)
+		if !qr.isInner {
+			return -1, -1
+		}
+
+		if qr.prefixLen > 0 && !qr.hasPrefixContent {
+			if nextID != -1 {
+				nextID = st.leftMost(nextID)
+			}
+			return nid, nextID
+		}
+
+		if qr.hasPrefixContent {
+			if prefixCompare(key[i>>3:], qr.prefix) != 0 {
+				return -1, -1
+			}
+			i = i&^7 + qr.prefixLen
+		}
+
+		lchID, has := st.getLEChildID(qr, i)
+		if has == 0 {
+			return -1, -1
+		}
+
+		_, end := st.getChildRange(qr)
+		if lchID+2 < end {
+			nextID = lchID + 2
+		}
+
+		if i == l {
+			return -1, -1
+		}
+
+		nid = lchID + 1
+		i += qr.wordSize
+	}
+}
+
+// Scan calls fn for every leaf in key order, in the same range as Iter() does.
+// The key is "" if SlimTrie is not created with Opt.Complete.
+// Scan stops if fn returns false.
+//
+// Since 0.5.11
+func (st *SlimTrie) Scan(from, to string, fn func(key string, value interface{}) bool) {
+	it := st.Iter(from, to)
+	for it.Next() {
+		if !fn(it.Key(), it.Value()) {
+			return
+		}
+	}
+}
+
+// Next moves the Iterator to the next leaf.
+// It must be called before accessing the first leaf.
+// It returns false if there is no more leaf.
+//
+// Since 0.5.11
+func (it *Iterator) Next() bool {
+
+	if it.nodeID == -1 {
+		return false
+	}
+
+	if !it.started {
+		it.started = true
+		return true
+	}
+
+	if it.nodeID == it.lastID {
+		it.nodeID = -1
+		return false
+	}
+
+	it.advance()
+
+	if it.nodeID == it.endID {
+		it.nodeID = -1
+	}
+	return it.nodeID != -1
+}
+
+// NodeID returns the node id of current leaf.
+//
+// Since 0.5.11
+func (it *Iterator) NodeID() int32 {
+	return it.nodeID
+}
+
+// Key returns the key of current leaf.
+// It returns "" if SlimTrie is not created with Opt.Complete.
+//
+// Since 0.5.11
+func (it *Iterator) Key() string {
+	if !it.withKey {
+		return ""
+	}
+	return string(it.key)
+}
+
+// Value returns the value of current leaf.
+//
+// Since 0.5.11
+func (it *Iterator) Value() interface{} {
+	return it.st.getLeaf(it.nodeID)
+}
+
+// seek builds frames along a path from root to a leaf.
+func (it *Iterator) seek(path []int32) {
+
+	i := int32(0)
+	last := len(path) - 1
+
+	for _, nid := range path[:last] {
+
+		*it.qr = querySession{}
+		it.st.getInner(nid, it.qr)
+		i = it.push(it.qr, i)
+
+		f := &it.frames[len(it.frames)-1]
+		for f.next <= path[len(it.frames)] {
+			f.next++
+			i = it.enterChild(f)
+		}
+	}
+
+	it.descend(path[last], i)
+}
+
+// advance moves to the next leaf in key order.
+func (it *Iterator) advance() {
+
+	for len(it.frames) > 0 {
+
+		f := &it.frames[len(it.frames)-1]
+		if f.next == f.end {
+			it.frames = it.frames[:len(it.frames)-1]
+			continue
+		}
+
+		nid := f.next
+		f.next++
+		i := it.enterChild(f)
+
+		it.descend(nid, i)
+		return
+	}
+
+	it.nodeID = -1
+}
+
+// descend walks down from node "nid" to its left most leaf.
+// "i" is the bit position in key where node "nid" starts.
+func (it *Iterator) descend(nid int32, i int32) {
+
+	for {
+		*it.qr = querySession{}
+		it.st.getInner(nid, it.qr)
+
+		if !it.qr.isInner {
+			break
+		}
+
+		i = it.push(it.qr, i)
+
+		f := &it.frames[len(it.frames)-1]
+		nid = f.next
+		f.next++
+		i = it.enterChild(f)
+	}
+
+	it.nodeID = nid
+
+	if it.withKey {
+		it.key = truncKey(it.key, i&^7)
+		if it.qr.hasLeafPrefix {
+			it.key = append(it.key, it.qr.leafPrefix...)
+		}
+	}
+}
+
+// push adds a frame of an inner node and returns the bit position where
+// the labels of this node start.
+func (it *Iterator) push(qr *querySession, i int32) int32 {
+
+	ns := it.st.nodes
+
+	if qr.hasPrefixContent {
+		if it.withKey {
+			it.key = truncKey(it.key, i&^7)
+			it.key = append(it.key, qr.prefix[1:]...)
+			it.key = truncKey(it.key, i&^7+qr.prefixLen)
+		}
+		i = i&^7 + qr.prefixLen
+	} else {
+		i += qr.prefixLen
+	}
+
+	first, end := it.st.getChildRange(qr)
+
+	it.frames = append(it.frames, iterFrame{
+		next:     first,
+		end:      end,
+		labelPos: i,
+		wordSize: qr.wordSize,
+		from:     qr.from,
+		to:       qr.to,
+		isShort:  qr.to-qr.from == ns.ShortSize,
+		bm:       qr.bm,
+		labelBit: -1,
+	})
+
+	return i
+}
+
+// enterChild moves to the label of the next child and returns the bit
+// position in key where the child starts.
+func (it *Iterator) enterChild(f *iterFrame) int32 {
+
+	if !it.withKey {
+		return 0
+	}
+
+	f.labelBit++
+	for !it.st.hasLabelBit(f) {
+		f.labelBit++
+	}
+
+	if f.labelBit == 0 {
+		// the empty label: a key ends at this node.
+		it.key = truncKey(it.key, f.labelPos)
+		return f.labelPos
+	}
+
+	it.key = appendLabel(it.key, f.labelPos, byte(f.labelBit-1), f.wordSize)
+	return f.labelPos + f.wordSize
+}
+
+// hasLabelBit checks if the label bit at f.labelBit is set.
+func (st *SlimTrie) hasLabelBit(f *iterFrame) bool {
+	if f.isShort {
+		return f.bm>>uint(f.labelBit)&1 != 0
+	}
+	ws := st.nodes.Inners.Words
+	p := f.from + f.labelBit
+	return ws[p>>6]>>uint(p&63)&1 != 0
+}
+
+// getChildRange returns the id of the first child and the last child plus 1
+// of an inner node.
+func (st *SlimTrie) getChildRange(qr *querySession) (int32, int32) {
+
+	ns := st.nodes
+
+	first, _ := bitmap.Rank128(ns.Inners.Words, ns.Inners.RankIndex, qr.from)
+	last, bit := bitmap.Rank128(ns.Inners.Words, ns.Inners.RankIndex, qr.to-1)
+
+	return first + 1, last + bit + 1
+}
+
+// pathLess compares two paths from root and returns true if the node path "a"
+// leads to is before the node "b" leads to, in key order.
+func (st *SlimTrie) pathLess(a, b []int32) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// truncKey truncates a key to n bits.
+// Trailing bits in the last byte are cleared.
+func truncKey(key []byte, n int32) []byte {
+	key = key[:(n+7)>>3]
+	if n&7 != 0 {
+		key[len(key)-1] &= byte(0xff) << uint(8-n&7)
+	}
+	return key
+}
+
+// appendLabel truncates key to "pos" bits and append a label of "wordSize"
+// bits.
+func appendLabel(key []byte, pos int32, label byte, wordSize int32) []byte {
+
+	key = truncKey(key, pos)
+
+	if wordSize == bigWordSize {
+		return append(key, label)
+	}
+
+	if pos&7 == 0 {
+		return append(key, label<<4)
+	}
+
+	key[len(key)-1] |= label
+	return key
+}
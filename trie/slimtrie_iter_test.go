@@ -0,0 +1,332 @@
+package trie
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/openacid/slim/encode"
+	"github.com/openacid/testkeys"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_parentOf(t *testing.T) {
+
+	ta := require.New(t)
+
+	for _, typ := range testkeys.AssetNames() {
+
+		keys := getKeys(typ)
+		if len(keys) >= 1000 {
+			continue
+		}
+
+		values := makeI32s(len(keys))
+		st, err := NewSlimTrie(encode.I32{}, keys, values)
+		ta.NoError(err)
+
+		ta.Equal(int32(-1), st.parentOf(0))
+
+		n := st.nodeCnt()
+		for nid := int32(0); nid < n; nid++ {
+			qr := &querySession{}
+			st.getInner(nid, qr)
+			if !qr.isInner {
+				continue
+			}
+
+			first, end := st.getChildRange(qr)
+			for chID := first; chID < end; chID++ {
+				ta.Equal(nid, st.parentOf(chID), "keys: %s, child: %d", typ, chID)
+			}
+		}
+	}
+}
+
+func TestSlimTrie_Iter_empty(t *testing.T) {
+
+	ta := require.New(t)
+
+	st, err := NewSlimTrie(encode.I32{}, []string{}, []int32{})
+	ta.NoError(err)
+
+	it := st.Iter("", "")
+	ta.False(it.Next())
+	ta.False(it.Next())
+}
+
+func TestSlimTrie_Iter_tiny(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := []string{
+		"abc",
+		"abcd",
+		"abd",
+		"abde",
+		"bc",
+		"bcd",
+		"bcde",
+		"cde",
+	}
+	values := makeI32s(len(keys))
+
+	cases := []struct {
+		from, to string
+		want     []int32
+	}{
+		{"", "", []int32{0, 1, 2, 3, 4, 5, 6, 7}},
+		{"abc", "", []int32{0, 1, 2, 3, 4, 5, 6, 7}},
+		{"abcc", "", []int32{1, 2, 3, 4, 5, 6, 7}},
+		{"abcd", "bcd", []int32{1, 2, 3, 4}},
+		{"abcde", "bcde", []int32{2, 3, 4, 5}},
+		{"b", "c", []int32{4, 5, 6}},
+		{"cde", "", []int32{7}},
+		{"cdf", "", []int32{}},
+		{"bcd", "bcd", []int32{}},
+		{"bcd", "abc", []int32{}},
+		{"", "a", []int32{}},
+	}
+
+	st, err := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	for i, c := range cases {
+
+		gotVals := []int32{}
+		gotKeys := []string{}
+
+		it := st.Iter(c.from, c.to)
+		for it.Next() {
+			gotVals = append(gotVals, it.Value().(int32))
+			gotKeys = append(gotKeys, it.Key())
+		}
+
+		wantKeys := []string{}
+		for _, v := range c.want {
+			wantKeys = append(wantKeys, keys[v])
+		}
+
+		ta.Equal(c.want, gotVals, "%d-th: case: %+v", i+1, c)
+		ta.Equal(wantKeys, gotKeys, "%d-th: case: %+v", i+1, c)
+	}
+}
+
+func TestSlimTrie_Iter_Complete(t *testing.T) {
+
+	ta := require.New(t)
+
+	for _, typ := range testkeys.AssetNames() {
+
+		keys := getKeys(typ)
+		if len(keys) >= 1000 {
+			continue
+		}
+
+		values := makeI32s(len(keys))
+		st, err := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+		ta.NoError(err)
+
+		// all keys
+
+		gotKeys := []string{}
+		gotVals := []int32{}
+		st.Scan("", "", func(key string, v interface{}) bool {
+			gotKeys = append(gotKeys, key)
+			gotVals = append(gotVals, v.(int32))
+			return true
+		})
+
+		ta.Equal(keys, gotKeys, "keys: %s", typ)
+		ta.Equal(values, gotVals, "keys: %s", typ)
+
+		// random bounds
+
+		bounds := append(randVStrings(100, 0, 10), keys...)
+		for i := 0; i < 200; i++ {
+			from := bounds[rand.Intn(len(bounds))]
+			to := bounds[rand.Intn(len(bounds))]
+
+			s := sort.SearchStrings(keys, from)
+			e := sort.SearchStrings(keys, to)
+			if to == "" {
+				e = len(keys)
+			}
+
+			want := []int32{}
+			for j := s; j < e; j++ {
+				want = append(want, values[j])
+			}
+
+			got := []int32{}
+			st.Scan(from, to, func(key string, v interface{}) bool {
+				ta.Equal(keys[v.(int32)], key)
+				got = append(got, v.(int32))
+				return true
+			})
+
+			ta.Equal(want, got, "keys: %s, from: %q, to: %q", typ, from, to)
+		}
+	}
+}
+
+func TestSlimTrie_Iter_Complete_DedupValue(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := []string{
+		"a",
+		"ab",
+		"abc",
+		"b",
+		"bc",
+	}
+	values := []int32{0, 0, 1, 1, 2}
+
+	st, err := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	gotKeys := []string{}
+	st.Scan("", "", func(key string, v interface{}) bool {
+		gotKeys = append(gotKeys, key)
+		return true
+	})
+
+	ta.Equal([]string{"a", "abc", "bc"}, gotKeys)
+}
+
+func TestSlimTrie_Iter_presentBounds(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+
+	st, err := NewSlimTrie(encode.I32{}, keys, values)
+	ta.NoError(err)
+
+	got := []int32{}
+	st.Scan("", "", func(key string, v interface{}) bool {
+		ta.Equal("", key)
+		got = append(got, v.(int32))
+		return true
+	})
+	ta.Equal(values, got)
+
+	for i := 0; i < 200; i++ {
+		s := rand.Intn(len(keys))
+		e := s + rand.Intn(100)
+		if e > len(keys)-1 {
+			e = len(keys) - 1
+		}
+
+		it := st.Iter(keys[s], keys[e])
+		got := []int32{}
+		for it.Next() {
+			got = append(got, it.Value().(int32))
+		}
+		testIterCovers(t, got, int32(s), int32(e))
+	}
+}
+
+func TestSlimTrie_Iter_absentBounds(t *testing.T) {
+
+	ta := require.New(t)
+
+	// "bbab" matches the leaf of "bbaa" since the last byte is not stored.
+	keys := []string{"a", "aa", "ab", "b", "ba", "bb", "bba", "bbaa", "bbaaab", "bbab", "c"}
+	values := makeI32s(len(keys))
+
+	st, err := NewSlimTrie(encode.I32{}, keys, values)
+	ta.NoError(err)
+
+	got := []int32{}
+	st.Scan("bbaa", "bbab", func(key string, v interface{}) bool {
+		got = append(got, v.(int32))
+		return true
+	})
+	testIterCovers(t, got, 7, 9)
+
+	opts := []Opt{
+		{},
+		{InnerPrefix: Bool(true)},
+		{LeafPrefix: Bool(true)},
+	}
+
+	for _, typ := range testkeys.AssetNames() {
+
+		keys := getKeys(typ)
+		if len(keys) == 0 || len(keys) >= 1000 {
+			continue
+		}
+		values := makeI32s(len(keys))
+
+		bounds := append(makeAbsentKeys(keys, 1000, 0, 20), "")
+		for i := 0; i < 1000; i++ {
+			k := keys[rand.Intn(len(keys))]
+			bounds = append(bounds, k, k[:rand.Intn(len(k)+1)])
+		}
+
+		for _, opt := range opts {
+
+			st, err := NewSlimTrie(encode.I32{}, keys, values, opt)
+			ta.NoError(err)
+
+			for i := 0; i < 2000; i++ {
+				from := bounds[rand.Intn(len(bounds))]
+				to := bounds[rand.Intn(len(bounds))]
+
+				s := sort.SearchStrings(keys, from)
+				e := len(keys)
+				if to != "" {
+					e = sort.SearchStrings(keys, to)
+				}
+
+				got := []int32{}
+				st.Scan(from, to, func(key string, v interface{}) bool {
+					got = append(got, v.(int32))
+					return true
+				})
+
+				if s < e {
+					testIterCovers(t, got, int32(s), int32(e))
+				}
+			}
+		}
+	}
+}
+
+// testIterCovers checks that leaves yielded by an Iterator are adjacent and
+// contain the i-th to the (e-1)-th leaf, with i-th leaf value i.
+func testIterCovers(t *testing.T, got []int32, s, e int32) {
+
+	ta := require.New(t)
+
+	ta.NotEmpty(got, "want: [%d, %d)", s, e)
+
+	for i := 1; i < len(got); i++ {
+		ta.Equal(got[i-1]+1, got[i], "not adjacent: %v", got)
+	}
+
+	ta.True(got[0] <= s, "want: [%d, %d), got: %v", s, e, got)
+	ta.True(got[len(got)-1] >= e-1, "want: [%d, %d), got: %v", s, e, got)
+}
+
+func TestSlimTrie_Scan_stop(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := []string{"a", "b", "c", "d"}
+	values := makeI32s(len(keys))
+
+	st, err := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	got := []int32{}
+	st.Scan("b", "", func(key string, v interface{}) bool {
+		got = append(got, v.(int32))
+		return len(got) < 2
+	})
+
+	ta.Equal([]int32{1, 2}, got)
+}
@@ -0,0 +1,56 @@
+package trie
+
+// KeyOf returns the key of a leaf node.
+// The key can only be rebuilt if SlimTrie is created with Opt.Complete.
+//
+// It returns false if SlimTrie does not store complete keys, or "nodeID" is
+// not a leaf.
+//
+// A leaf node id can be found with GetID() or Iterator.NodeID().
+//
+// Since 0.5.11
+func (st *SlimTrie) KeyOf(nodeID int32) (string, bool) {
+
+	if st.nodes.NodeTypeBM == nil || !st.isComplete() {
+		return "", false
+	}
+
+	if nodeID < 0 || nodeID >= st.nodeCnt() {
+		return "", false
+	}
+
+	_, isInner := st.getLeafIndex(nodeID)
+	if isInner == 1 {
+		return "", false
+	}
+
+	it := &Iterator{
+		st:      st,
+		qr:      &querySession{},
+		withKey: true,
+	}
+	it.seek(st.pathOf(nodeID))
+
+	return string(it.key), true
+}
+
+// Keys returns all keys in key order.
+// It returns nil if SlimTrie is not created with Opt.Complete.
+//
+// With Opt.DedupValue a key with the same value as the previous one is not
+// stored thus it is not returned.
+//
+// Since 0.5.11
+func (st *SlimTrie) Keys() []string {
+
+	if !st.isComplete() {
+		return nil
+	}
+
+	keys := []string{}
+	st.Scan("", "", func(key string, value interface{}) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return keys
+}
@@ -0,0 +1,93 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/openacid/slim/encode"
+	"github.com/openacid/testkeys"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_KeyOf(t *testing.T) {
+
+	ta := require.New(t)
+
+	for _, typ := range testkeys.AssetNames() {
+
+		keys := getKeys(typ)
+		if len(keys) >= 1000 {
+			continue
+		}
+
+		values := makeI32s(len(keys))
+		st, err := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+		ta.NoError(err)
+
+		for _, key := range keys {
+			nid := st.GetID(key)
+			got, found := st.KeyOf(nid)
+			ta.True(found, "keys: %s, key: %q", typ, key)
+			ta.Equal(key, got, "keys: %s, key: %q", typ, key)
+		}
+
+		if len(keys) > 0 {
+			ta.Equal(keys, st.Keys(), "keys: %s", typ)
+		}
+	}
+}
+
+func TestSlimTrie_KeyOf_notFound(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := []string{
+		"abc",
+		"abcd",
+		"abd",
+		"abde",
+		"bc",
+		"bcd",
+		"bcde",
+		"cde",
+	}
+	values := makeI32s(len(keys))
+
+	st, err := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	cases := []int32{-1, 0, 1, 7, 14, 100}
+	for _, nid := range cases {
+		_, found := st.KeyOf(nid)
+		ta.False(found, "nodeID: %d", nid)
+	}
+
+	// leaf prefix only does not store complete key
+
+	st, err = NewSlimTrie(encode.I32{}, keys, values, Opt{LeafPrefix: Bool(true)})
+	ta.NoError(err)
+
+	_, found := st.KeyOf(st.GetID("abc"))
+	ta.False(found)
+	ta.Nil(st.Keys())
+
+	// empty
+
+	st, err = NewSlimTrie(encode.I32{}, []string{}, []int32{}, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	_, found = st.KeyOf(0)
+	ta.False(found)
+	ta.Empty(st.Keys())
+}
+
+func TestSlimTrie_KeyOf_onekey(t *testing.T) {
+
+	ta := require.New(t)
+
+	st, err := NewSlimTrie(encode.I32{}, []string{"abc"}, []int32{1}, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	got, found := st.KeyOf(0)
+	ta.True(found)
+	ta.Equal("abc", got)
+}
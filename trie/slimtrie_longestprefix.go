@@ -0,0 +1,173 @@
+package trie
+
+// LongestPrefix finds the longest key in SlimTrie that is a prefix of key.
+//
+// It returns the length of the matched key, its value and true if there is
+// one.
+//
+// With Opt.Complete the result is exact.
+// Otherwise, just like Get(), it may return a false positive: a key that is
+// not stored, or a stored key that is not a prefix of key.
+// Without Opt.LeafPrefix the length of a key ending at a leaf is unknown:
+// the shortest possible length is returned, or with Opt.FingerprintBits, the
+// longest length whose fingerprint matches.
+//
+//	st, _ := NewSlimTrie(encode.I32{}, []string{"/a", "/a/b"}, []int32{1, 2}, Opt{Complete: Bool(true)})
+//	st.LongestPrefix("/a/bc") // 4, 2, true
+//	st.LongestPrefix("/a/c")  // 2, 1, true
+//
+// Since 0.5.11
+func (st *SlimTrie) LongestPrefix(key string) (matchedLen int, value interface{}, found bool) {
+
+	id, n := st.longestPrefixID(key)
+	if id == -1 {
+		return 0, nil, false
+	}
+
+	return int(n), st.getLeaf(id), true
+}
+
+// longestPrefixID walks down the path of key just like getID.
+// Every key that ends at an inner node on the path is a prefix of key.
+// It returns the leaf node id of the longest one and its length in byte, or
+// -1 if there is no such key.
+func (st *SlimTrie) longestPrefixID(key string) (int32, int32) {
+
+	if st.nodes.NodeTypeBM == nil {
+		return -1, 0
+	}
+
+	qr := &querySession{}
+
+	l := int32(8 * len(key))
+	qr.keyBitLen = l
+	qr.key = key
+
+	foundID, foundLen := int32(-1), int32(0)
+	eqID, i := int32(0), int32(0)
+
+	for {
+
+		qr.isInner = false
+		qr.prefixLen = 0
+		qr.hasPrefixContent = false
+		qr.hasLeafPrefix = false
+
+		st.getInner(eqID, qr)
+		if !qr.isInner {
+			// leaf
+			break
+		}
+
+		if qr.hasPrefixContent {
+			r := prefixCompare(key[i>>3:], qr.prefix)
+			if r != 0 {
+				return foundID, foundLen
+			}
+			i = i&(^7) + qr.prefixLen
+		} else {
+			i += qr.prefixLen
+		}
+
+		if i > l {
+			return foundID, foundLen
+		}
+
+		if i&7 == 0 {
+			// a key ends at this node.
+			endID := st.endChildID(qr)
+			if endID != -1 && st.matchEnd(endID, key[:i>>3]) {
+				foundID, foundLen = endID, i>>3
+			}
+		}
+
+		if i == l {
+			return foundID, foundLen
+		}
+
+		lchID, has := st.getLEChildID(qr, i)
+		if has == 0 {
+			return foundID, foundLen
+		}
+		eqID = lchID + 1
+
+		i += qr.wordSize
+	}
+
+	// the leaf on the path of key.
+
+	if st.nodes.LeafPrefixes == nil {
+		return st.matchUnknownEnd(eqID, key, i, foundID, foundLen)
+	}
+
+	var lp []byte
+	if qr.hasLeafPrefix {
+		lp = qr.leafPrefix
+	}
+
+	tail := key[i>>3:]
+	if len(tail) < len(lp) || tail[:len(lp)] != string(lp) {
+		return foundID, foundLen
+	}
+	n := i>>3 + int32(len(lp))
+
+	if !st.matchEnd(eqID, key[:n]) {
+		return foundID, foundLen
+	}
+
+	return eqID, n
+}
+
+// matchUnknownEnd matches a leaf whose key length is unknown, because leaf
+// prefixes are not stored.
+// The key of the leaf has at least the "i" bits on the path.
+//
+// Without fingerprint the shortest possible length is returned.
+// With fingerprint, every length from the longest is tried.
+// If no length matches, it returns foundID and foundLen.
+func (st *SlimTrie) matchUnknownEnd(nodeid int32, key string, i int32, foundID, foundLen int32) (int32, int32) {
+
+	minLen := (i + 7) >> 3
+
+	if st.nodes.FingerprintBits == 0 {
+		return nodeid, minLen
+	}
+
+	for n := int32(len(key)); n >= minLen; n-- {
+		if st.matchFingerprint(nodeid, key[:n]) {
+			return nodeid, n
+		}
+	}
+
+	return foundID, foundLen
+}
+
+// endChildID returns the id of the child with the empty label, i.e., the leaf
+// of the key that ends at the inner node in qr.
+// It returns -1 if there is no such child.
+func (st *SlimTrie) endChildID(qr *querySession) int32 {
+
+	if qr.to-qr.from == st.nodes.ShortSize {
+		if qr.bm&1 == 0 {
+			return -1
+		}
+	} else {
+		ws := st.nodes.Inners.Words
+		if ws[qr.from>>6]>>uint(qr.from&63)&1 == 0 {
+			return -1
+		}
+	}
+
+	first, _ := st.getChildRange(qr)
+	return first
+}
+
+// matchEnd checks if a leaf, which ends exactly at the end of key, matches
+// key by fingerprint, if there is.
+func (st *SlimTrie) matchEnd(nodeid int32, key string) bool {
+
+	if st.nodes.FingerprintBits > 0 {
+		return st.matchFingerprint(nodeid, key)
+	}
+	return true
+}
@@ -0,0 +1,192 @@
+package trie
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/openacid/slim/encode"
+	"github.com/openacid/testkeys"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_LongestPrefix(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := []string{
+		"",
+		"/a",
+		"/a/b",
+		"/a/b/cd",
+		"/ab",
+		"10.0.",
+		"10.0.1.",
+		"10.1.",
+	}
+	values := makeI32s(len(keys))
+
+	cases := []struct {
+		key       string
+		wantLen   int
+		wantValue interface{}
+		wantFound bool
+	}{
+		{"", 0, int32(0), true},
+		{"/", 0, int32(0), true},
+		{"/a", 2, int32(1), true},
+		{"/a/", 2, int32(1), true},
+		{"/a/b", 4, int32(2), true},
+		{"/a/b/c", 4, int32(2), true},
+		{"/a/b/cd", 7, int32(3), true},
+		{"/a/b/cde", 7, int32(3), true},
+		{"/abc", 3, int32(4), true},
+		{"10.0.2.1", 5, int32(5), true},
+		{"10.0.1.1", 7, int32(6), true},
+		{"10.1.1.1", 5, int32(7), true},
+		{"10.2.1.1", 0, int32(0), true},
+	}
+
+	for _, opt := range []Opt{{Complete: Bool(true)}, {Complete: Bool(true), DedupValue: Bool(false)}} {
+
+		st, err := NewSlimTrie(encode.I32{}, keys, values, opt)
+		ta.NoError(err)
+
+		for i, c := range cases {
+			n, v, found := st.LongestPrefix(c.key)
+			ta.Equal(c.wantLen, n, "%d-th: case: %+v", i+1, c)
+			ta.Equal(c.wantValue, v, "%d-th: case: %+v", i+1, c)
+			ta.Equal(c.wantFound, found, "%d-th: case: %+v", i+1, c)
+		}
+	}
+
+	// without the empty key
+
+	st, err := NewSlimTrie(encode.I32{}, keys[1:], values[1:], Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	for _, k := range []string{"", "/", "10.2.1.1", "a"} {
+		n, v, found := st.LongestPrefix(k)
+		ta.Equal(0, n, "key: %q", k)
+		ta.Nil(v, "key: %q", k)
+		ta.False(found, "key: %q", k)
+	}
+}
+
+func TestSlimTrie_LongestPrefix_empty(t *testing.T) {
+
+	ta := require.New(t)
+
+	st, err := NewSlimTrie(encode.I32{}, nil, nil)
+	ta.NoError(err)
+
+	_, _, found := st.LongestPrefix("a")
+	ta.False(found)
+}
+
+func TestSlimTrie_LongestPrefix_Complete(t *testing.T) {
+
+	ta := require.New(t)
+
+	for _, typ := range testkeys.AssetNames() {
+
+		keys := getKeys(typ)
+		if len(keys) >= 1000 {
+			continue
+		}
+
+		values := makeI32s(len(keys))
+
+		// query with present keys, their prefixes, and keys extended from them.
+		queries := makeAbsentKeys(keys, len(keys), 0, 20)
+		for _, k := range keys {
+			queries = append(queries, k, k+"x", k+"\x00", k[:len(k)/2])
+		}
+
+		longest := func(key string) (int, interface{}, bool) {
+			for i := len(keys) - 1; i >= 0; i-- {
+				if strings.HasPrefix(key, keys[i]) {
+					return len(keys[i]), values[i], true
+				}
+			}
+			return 0, nil, false
+		}
+
+		st, err := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true), DedupValue: Bool(false)})
+		ta.NoError(err)
+
+		for _, key := range queries {
+
+			wantLen, wantValue, wantFound := longest(key)
+			n, v, found := st.LongestPrefix(key)
+
+			ta.Equal(wantFound, found, "keys: %s, key: %q", typ, key)
+			ta.Equal(wantLen, n, "keys: %s, key: %q", typ, key)
+			ta.Equal(wantValue, v, "keys: %s, key: %q", typ, key)
+		}
+	}
+}
+
+func TestSlimTrie_LongestPrefix_presentKeys(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("200kweb2")[:20000]
+	values := makeI32s(len(keys))
+
+	opts := []Opt{
+		{},
+		{InnerPrefix: Bool(true)},
+		{LeafPrefix: Bool(true)},
+		{FingerprintBits: 16},
+		{InnerPrefix: Bool(true), FingerprintBits: 16},
+	}
+
+	for _, opt := range opts {
+
+		st, err := NewSlimTrie(encode.I32{}, keys, values, opt)
+		ta.NoError(err)
+
+		// a present key is always found, although without Opt.Complete it may
+		// match a shorter key by false positive.
+		for i, k := range keys {
+			for _, q := range []string{k, k + "/x"} {
+				n, v, found := st.LongestPrefix(q)
+				ta.True(found, "opt: %+v, key: %q", opt, q)
+				ta.True(n <= len(q), "opt: %+v, key: %q", opt, q)
+				if n == len(k) {
+					ta.Equal(values[i], v, "opt: %+v, key: %q", opt, q)
+				}
+			}
+		}
+	}
+}
+
+func TestSlimTrie_LongestPrefix_unknownLeafLen(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := []string{"/a", "/b"}
+	values := makeI32s(len(keys))
+
+	cases := []struct {
+		opt     Opt
+		wantLen int
+	}{
+		// the shortest possible length: the branch to "/a" is at the second
+		// byte.
+		{Opt{}, 2},
+		{Opt{FingerprintBits: 16}, 2},
+		{Opt{Complete: Bool(true)}, 2},
+	}
+
+	for i, c := range cases {
+
+		st, err := NewSlimTrie(encode.I32{}, keys, values, c.opt)
+		ta.NoError(err)
+
+		n, v, found := st.LongestPrefix("/a/x")
+		ta.True(found, "%d-th: opt: %+v", i+1, c.opt)
+		ta.Equal(c.wantLen, n, "%d-th: opt: %+v", i+1, c.opt)
+		ta.Equal(int32(0), v, "%d-th: opt: %+v", i+1, c.opt)
+	}
+}
@@ -4,14 +4,48 @@ import (
 	"bytes"
 	"encoding/binary"
 	fmt "fmt"
+	"hash/crc32"
+	"io"
 	"strings"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/openacid/errors"
 	"github.com/openacid/low/bitmap"
 	"github.com/openacid/low/pbcmpl"
 	"github.com/openacid/low/vers"
 	"github.com/openacid/must"
 	"github.com/openacid/slim/array"
+	"github.com/openacid/slim/encode"
+)
+
+// checksumHeader is the pbcmpl header extended with a checksum of the body
+// and the name of the value encoder.
+// It is written since 0.5.11.
+// A pbcmpl header without checksum is 32 bytes, this one is 40 bytes followed
+// by the encoder name padded to 8 bytes.
+type checksumHeader struct {
+	Version    [16]byte
+	HeaderSize uint64
+	BodySize   uint64
+
+	// Checksum is the CRC32C of the whole header with Checksum set to 0, and
+	// of the body, thus a corrupted Version, BodySize or encoder name is also
+	// detected.
+	Checksum uint32
+
+	// EncoderNameLen is the length of the encoder name by encode.NameOf(),
+	// or 0 if there is no encoder or it is not registered.
+	EncoderNameLen uint32
+}
+
+// maxEncoderNameLen is the max length of an encoder name in a header.
+const maxEncoderNameLen = 1024
+
+var (
+	pbcmplHeaderSize   = int64(pbcmpl.HeaderSize(nil))
+	checksumHeaderSize = int64(binary.Size(&checksumHeader{}))
+
+	crc32c = crc32.MakeTable(crc32.Castagnoli)
 )
 
 // Marshal serializes it to byte stream.
@@ -21,46 +55,173 @@ func (st *SlimTrie) Marshal() ([]byte, error) {
 	var buf []byte
 	writer := bytes.NewBuffer(buf)
 
-	_, err := pbcmpl.Marshal(writer, st.nodes)
+	_, err := st.WriteTo(writer)
 	if err != nil {
-		return nil, errors.WithMessage(err, "failed to marshal st.nodes")
+		return nil, err
 	}
 
 	return writer.Bytes(), nil
 }
 
+// WriteTo writes the serialized SlimTrie to w, in the same format as
+// Marshal().
+// It implements io.WriterTo.
+//
+// It does not stream: the body is encoded into a buffer before being written
+// to w, thus it needs as much memory as Marshal() does.
+// It only saves the copy to the returned []byte of Marshal().
+//
+// Since 0.5.11
+func (st *SlimTrie) WriteTo(w io.Writer) (int64, error) {
+
+	body, err := proto.Marshal(st.nodes)
+	if err != nil {
+		return 0, errors.WithMessage(err, "failed to marshal st.nodes")
+	}
+
+	name := encoderName(st.encoder)
+	nameBuf := make([]byte, (len(name)+7)&^7)
+	copy(nameBuf, name)
+
+	h := &checksumHeader{
+		HeaderSize:     uint64(checksumHeaderSize) + uint64(len(nameBuf)),
+		BodySize:       uint64(len(body)),
+		EncoderNameLen: uint32(len(name)),
+	}
+	copy(h.Version[:], st.nodes.GetVersion())
+
+	hbuf := bytes.NewBuffer(make([]byte, 0, h.HeaderSize))
+	// writing to a bytes.Buffer never fails
+	binary.Write(hbuf, binary.LittleEndian, h)
+	hbuf.Write(nameBuf)
+
+	crc := crc32.Checksum(hbuf.Bytes(), crc32c)
+	crc = crc32.Update(crc, crc32c, body)
+	binary.LittleEndian.PutUint32(hbuf.Bytes()[pbcmplHeaderSize:], crc)
+
+	n, err := w.Write(hbuf.Bytes())
+	if err != nil {
+		return int64(n), errors.WithStack(err)
+	}
+
+	n2, err := w.Write(body)
+	n += n2
+	if err != nil {
+		return int64(n), errors.WithStack(err)
+	}
+
+	return int64(n), nil
+}
+
 // Unmarshal a SlimTrie from a byte stream.
 //
+// Since 0.5.11 the name of the encoder is stored in marshaled data, if the
+// encoder is registered in package encode.
+// If st has no encoder, it creates one by the name.
+// If st has a different encoder, it returns ErrEncoderMismatch.
+//
 // Since 0.4.3
 func (st *SlimTrie) Unmarshal(buf []byte) error {
+	_, err := st.ReadFrom(bytes.NewReader(buf))
+	return err
+}
+
+// Load creates a SlimTrie from data serialized by Marshal() or WriteTo(),
+// with the encoder recorded in the data.
+// Thus a caller does not need to know what encoder the SlimTrie is created
+// with.
+//
+// It returns an error wrapping encode.ErrUnknownEncoder if the encoder is not
+// registered, or if the SlimTrie has values but no encoder name is recorded,
+// e.g., it is created with an encoder not registered, or by an older version.
+//
+// opts is used only for Opt.ValidateOnLoad.
+//
+// Since 0.5.11
+func Load(buf []byte, opts ...Opt) (*SlimTrie, error) {
+
+	st, err := NewSlimTrie(nil, nil, nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	err = st.Unmarshal(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	if st.encoder == nil && st.nodes.Leaves != nil {
+		return nil, errors.Wrapf(encode.ErrUnknownEncoder, "no encoder name in marshaled data")
+	}
+
+	return st, nil
+}
+
+// ReadFrom reads a SlimTrie serialized by WriteTo() or Marshal() from r.
+// It reads exactly the bytes of one SlimTrie, thus r can be a stream with
+// other data following it.
+// It implements io.ReaderFrom.
+//
+// Since 0.5.11 the header has a checksum of the data.
+// It returns ErrChecksumMismatch if the data is corrupted.
+// Data without checksum, i.e., marshaled by an older version, is loaded
+// without verification.
+//
+// Since 0.5.11
+func (st *SlimTrie) ReadFrom(r io.Reader) (int64, error) {
+
+	n, err := st.readFrom(r)
+	if err != nil {
+		return n, err
+	}
+
+	if st.validateOnLoad {
+		err = st.Validate()
+		if err != nil {
+			st.nodes = &Nodes{}
+			return n, err
+		}
+	}
+
+	return n, nil
+}
+
+func (st *SlimTrie) readFrom(r io.Reader) (int64, error) {
 
 	st.nodes = &Nodes{}
 
-	reader := bytes.NewReader(buf)
+	// the header is read twice: once to check the version and once by
+	// pbcmpl.Unmarshal.
+	header := &bytes.Buffer{}
 
-	_, h, err := pbcmpl.ReadHeader(reader)
+	n, h, err := pbcmpl.ReadHeader(io.TeeReader(r, header))
 	if err != nil {
-		return errors.WithMessage(err, "failed to unmarshal header")
+		return n, errors.WithMessage(err, "failed to unmarshal header")
 	}
 
 	ver := h.GetVersion()
 	compatible := st.compatibleVersions()
 
 	if !vers.IsCompatible(ver, compatible) {
-		return errors.Wrapf(ErrIncompatible,
+		return n, errors.Wrapf(ErrIncompatible,
 			fmt.Sprintf(`version: "%s", compatible versions:"%s"`,
 				ver,
 				strings.Join(compatible, " || ")))
 	}
 
-	reader = bytes.NewReader(buf)
+	if h.GetHeaderSize() != pbcmplHeaderSize {
+		n2, err := st.readChecksummed(r, h, header.Bytes())
+		return n + n2, err
+	}
+
+	reader := io.MultiReader(header, r)
 
-	if vers.Check(ver, slimtrieVersion) {
-		_, _, err := pbcmpl.Unmarshal(reader, st.nodes)
+	if vers.Check(ver, "==0.5.10", "=="+slimtrieVersion) {
+		n, _, err := pbcmpl.Unmarshal(reader, st.nodes)
 		if err != nil {
-			return errors.WithMessage(err, "failed to unmarshal nodes")
+			return n, errors.WithMessage(err, "failed to unmarshal nodes")
 		}
-		return nil
+		return n, nil
 	}
 
 	// ver: "==1.0.0 || <0.5.10"
@@ -70,28 +231,142 @@ func (st *SlimTrie) Unmarshal(buf []byte) error {
 	leaves := &array.Array{}
 	leaves.EltEncoder = st.encoder
 
-	_, _, err = pbcmpl.Unmarshal(reader, children)
+	n, _, err = pbcmpl.Unmarshal(reader, children)
 	if err != nil {
-		return errors.WithMessage(err, "failed to unmarshal children")
+		return n, errors.WithMessage(err, "failed to unmarshal children")
 	}
 
-	_, _, err = pbcmpl.Unmarshal(reader, steps)
+	n2, _, err := pbcmpl.Unmarshal(reader, steps)
+	n += n2
 	if err != nil {
-		return errors.WithMessage(err, "failed to unmarshal steps")
+		return n, errors.WithMessage(err, "failed to unmarshal steps")
 	}
 
-	_, _, err = pbcmpl.Unmarshal(reader, leaves)
+	n2, _, err = pbcmpl.Unmarshal(reader, leaves)
+	n += n2
 	if err != nil {
-		return errors.WithMessage(err, "failed to unmarshal leaves")
+		return n, errors.WithMessage(err, "failed to unmarshal leaves")
 	}
 
 	// backward compatible:
 
 	before000510(st, ver, children, steps, leaves)
 
+	return n, nil
+}
+
+// readChecksummed reads the rest of a checksumHeader and the body following it
+// from r, and verifies the checksum.
+// h is the pbcmpl header already read and hbuf is its raw bytes.
+//
+// The body is read into a buffer growing with the data actually read, thus a
+// corrupted BodySize does not allocate a huge buffer.
+func (st *SlimTrie) readChecksummed(r io.Reader, h pbcmpl.Header, hbuf []byte) (int64, error) {
+
+	hsize := h.GetHeaderSize()
+	if hsize < checksumHeaderSize || hsize > checksumHeaderSize+maxEncoderNameLen {
+		return 0, errors.Wrapf(ErrMalformed, "header size: %d", hsize)
+	}
+
+	var ext [8]byte
+	n, err := io.ReadFull(r, ext[:checksumHeaderSize-pbcmplHeaderSize])
+	if err != nil {
+		return int64(n), errors.WithMessage(errors.WithStack(err), "failed to read checksum")
+	}
+
+	want := binary.LittleEndian.Uint32(ext[:4])
+	nameLen := int64(binary.LittleEndian.Uint32(ext[4:]))
+
+	if (nameLen+7)&^7 != hsize-checksumHeaderSize {
+		return int64(n), errors.Wrapf(ErrMalformed, "header size: %d, encoder name length: %d", hsize, nameLen)
+	}
+
+	nameBuf := make([]byte, hsize-checksumHeaderSize)
+	n2, err := io.ReadFull(r, nameBuf)
+	n += n2
+	if err != nil {
+		return int64(n), errors.WithMessage(errors.WithStack(err), "failed to read encoder name")
+	}
+
+	size := h.GetBodySize()
+	if size < 0 {
+		return int64(n), errors.Wrapf(ErrMalformed, "body size: %d", size)
+	}
+
+	body := &bytes.Buffer{}
+	bodyN, err := body.ReadFrom(io.LimitReader(r, size))
+	total := int64(n) + bodyN
+	if err != nil {
+		return total, errors.WithMessage(errors.WithStack(err), "failed to read nodes")
+	}
+	if bodyN != size {
+		return total, errors.Wrapf(ErrMalformed, "body size: %d, read: %d", size, bodyN)
+	}
+
+	// the checksum is calculated with the Checksum field set to 0
+	binary.LittleEndian.PutUint32(ext[:4], 0)
+
+	got := crc32.Checksum(hbuf, crc32c)
+	got = crc32.Update(got, crc32c, ext[:])
+	got = crc32.Update(got, crc32c, nameBuf)
+	got = crc32.Update(got, crc32c, body.Bytes())
+	if got != want {
+		return total, errors.Wrapf(ErrChecksumMismatch, "crc32c: %08x, want: %08x", got, want)
+	}
+
+	err = st.setEncoder(string(nameBuf[:nameLen]))
+	if err != nil {
+		return total, err
+	}
+
+	err = proto.Unmarshal(body.Bytes(), st.nodes)
+	if err != nil {
+		return total, errors.WithMessage(errors.WithStack(err), "failed to unmarshal nodes")
+	}
+
+	return total, nil
+}
+
+// setEncoder checks the encoder of a SlimTrie against the encoder name in
+// marshaled data.
+// If the SlimTrie has no encoder, it creates one by the name.
+func (st *SlimTrie) setEncoder(name string) error {
+
+	if name == "" {
+		return nil
+	}
+
+	if st.encoder == nil {
+		e, err := encode.ByName(name)
+		if err != nil {
+			return err
+		}
+		st.encoder = e
+		return nil
+	}
+
+	// A SlimTrie with an unregistered encoder can not be checked.
+	have := encoderName(st.encoder)
+	if have != "" && have != name {
+		return errors.Wrapf(ErrEncoderMismatch, "encoder: %s, marshaled with: %s", have, name)
+	}
+
 	return nil
 }
 
+// encoderName returns the registered name of e, or "" if e is nil or not
+// registered.
+func encoderName(e encode.Encoder) string {
+	if e == nil {
+		return ""
+	}
+	name, err := encode.NameOf(e)
+	if err != nil {
+		return ""
+	}
+	return name
+}
+
 // ProtoMessage implements proto.Message
 //
 // Since 0.4.3
@@ -129,7 +404,7 @@ func before000510ToNewChildrenArray(st *SlimTrie, ver string, ch *array.Array32,
 		}
 
 		// before 0.5.10 it stores steps only, no prefix
-		c := newCreator(64, true, normalizeOpt(&Opt{}))
+		c := newCreator(true, normalizeOpt(&Opt{}))
 
 		// before 0.5.10 there is no big inner
 		c.isBig = false
@@ -2,6 +2,8 @@ package trie
 
 import (
 	"bytes"
+	"encoding/binary"
+	"hash/crc32"
 	"io/ioutil"
 	"path/filepath"
 	"reflect"
@@ -61,7 +63,10 @@ func TestSlimTrie_Unmarshal_incompatible(t *testing.T) {
 		want  error
 	}{
 		{slimtrieVersion, nil},
-		{"0.5.11", ErrIncompatible},
+		// compatible but version is covered by checksum, 0.5.10 data without
+		// checksum is tested in TestSlimTrie_Unmarshal_checksum.
+		{"0.5.10", ErrChecksumMismatch},
+		{"0.5.12", ErrIncompatible},
 		{"0.6.0", ErrIncompatible},
 		{"0.9.9", ErrIncompatible},
 		{"1.0.1", ErrIncompatible},
@@ -187,6 +192,37 @@ func TestSlimTrie_Marshal_allkeys(t *testing.T) {
 	}
 }
 
+func TestSlimTrie_MarshalUnmarshal_varLenValues(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	values := make([]string, len(keys))
+	for i := range keys {
+		values[i] = strings.Repeat("v", i%7)
+	}
+
+	st1, err := NewSlimTrie(encode.String16{}, keys, values)
+	ta.NoError(err)
+	ta.NotNil(st1.nodes.Leaves.PositionBM)
+
+	buf, err := st1.Marshal()
+	ta.NoError(err)
+
+	st2, err := NewSlimTrie(encode.String16{}, nil, nil)
+	ta.NoError(err)
+
+	err = st2.Unmarshal(buf)
+	ta.NoError(err)
+	slimtrieEqual(st1, st2, t)
+
+	for i, key := range keys {
+		v, found := st2.Get(key)
+		ta.True(found, "key: %q", key)
+		ta.Equal(values[i], v, "key: %q", key)
+	}
+}
+
 func TestSlimTrie_Unmarshal_old_data(t *testing.T) {
 
 	iambig(t)
@@ -335,3 +371,255 @@ func TestSlimTrie_Unmarshal_0_5_3(t *testing.T) {
 		ta.Equal(ex.want, rst)
 	}
 }
+
+func TestSlimTrie_WriteToReadFrom(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys1 := getKeys("20kvl10")
+	keys2 := getKeys("10vl5")
+
+	st1, err := NewSlimTrie(encode.I32{}, keys1, makeI32s(len(keys1)))
+	ta.NoError(err)
+	st2, err := NewSlimTrie(encode.I32{}, keys2, makeI32s(len(keys2)), Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	// two tries in one stream
+
+	w := &bytes.Buffer{}
+
+	n1, err := st1.WriteTo(w)
+	ta.NoError(err)
+	n2, err := st2.WriteTo(w)
+	ta.NoError(err)
+	ta.Equal(int64(w.Len()), n1+n2)
+
+	b, err := st1.Marshal()
+	ta.NoError(err)
+	ta.Equal(b, w.Bytes()[:n1])
+
+	r1, err := NewSlimTrie(encode.I32{}, nil, nil)
+	ta.NoError(err)
+	n, err := r1.ReadFrom(w)
+	ta.NoError(err)
+	ta.Equal(n1, n)
+	slimtrieEqual(st1, r1, t)
+
+	r2, err := NewSlimTrie(encode.I32{}, nil, nil)
+	ta.NoError(err)
+	n, err = r2.ReadFrom(w)
+	ta.NoError(err)
+	ta.Equal(n2, n)
+	slimtrieEqual(st2, r2, t)
+
+	ta.Equal(0, w.Len())
+
+	// truncated
+
+	for _, size := range []int{0, 10, 40, len(b) - 1} {
+		_, err = r1.ReadFrom(bytes.NewReader(b[:size]))
+		ta.Error(err, "size: %d", size)
+	}
+
+	// data of old versions, followed by other data
+
+	b, err = ioutil.ReadFile("testdata/slimtrie-data-20kvl10-0.5.9")
+	ta.NoError(err)
+
+	r := bytes.NewReader(append(b, "foo"...))
+	n, err = r1.ReadFrom(r)
+	ta.NoError(err)
+	ta.Equal(int64(len(b)), n)
+	ta.Equal(3, r.Len())
+
+	testPresentKeysGRS(t, r1, keys1, makeI32s(len(keys1)))
+}
+
+func TestSlimTrie_Unmarshal_checksum(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("10vl5")
+	values := makeI32s(len(keys))
+
+	st1, err := NewSlimTrie(encode.I32{}, keys, values)
+	ta.NoError(err)
+
+	buf, err := st1.Marshal()
+	ta.NoError(err)
+
+	r := bytes.NewBuffer(buf)
+	_, h, err := pbcmpl.ReadHeader(r)
+	ta.NoError(err)
+	// 40 bytes and "i32/1" padded to 8 bytes
+	ta.Equal(int64(48), h.GetHeaderSize())
+	ta.Equal(int64(len(buf)-48), h.GetBodySize())
+	ta.Equal("i32/1", string(buf[40:45]))
+
+	st2, err := NewSlimTrie(encode.I32{}, nil, nil)
+	ta.NoError(err)
+
+	// flip a bit in body, in encoder name or in checksum
+
+	for _, i := range []int{32, 35, 40, 47, 48, len(buf) / 2, len(buf) - 1} {
+		bad := append([]byte{}, buf...)
+		bad[i] ^= 0x10
+
+		err := st2.Unmarshal(bad)
+		ta.Equal(ErrChecksumMismatch, errors.Cause(err), "flip byte: %d", i)
+	}
+
+	// Version and BodySize are also covered by checksum
+
+	bad := append([]byte{}, buf...)
+	copy(bad, "0.5.10")
+	err = st2.Unmarshal(bad)
+	ta.Equal(ErrChecksumMismatch, errors.Cause(err))
+
+	bad = append([]byte{}, buf...)
+	binary.LittleEndian.PutUint64(bad[24:], uint64(len(buf)-49))
+	err = st2.Unmarshal(bad)
+	ta.Equal(ErrChecksumMismatch, errors.Cause(err))
+
+	// a huge BodySize does not allocate a huge buffer
+
+	bad = append([]byte{}, buf...)
+	binary.LittleEndian.PutUint64(bad[24:], 1<<62)
+	err = st2.Unmarshal(bad)
+	ta.Equal(ErrMalformed, errors.Cause(err))
+
+	// invalid header size or encoder name length
+
+	for _, i := range []int{16, 36} {
+		bad := append([]byte{}, buf...)
+		bad[i] = 36
+		err = st2.Unmarshal(bad)
+		ta.Equal(ErrMalformed, errors.Cause(err), "set byte: %d", i)
+	}
+
+	// 0.5.10 data has no checksum
+
+	w := &bytes.Buffer{}
+	_, err = pbcmpl.Marshal(w, st1.nodes)
+	ta.NoError(err)
+
+	old := w.Bytes()
+	copy(old, append([]byte("0.5.10"), make([]byte, 10)...))
+
+	ta.NoError(st2.Unmarshal(old))
+	slimtrieEqual(st1, st2, t)
+	testPresentKeysGet(t, st2, keys, values)
+}
+
+func TestLoad(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+
+	ints := make([]int, len(keys))
+	for i := range ints {
+		ints[i] = i
+	}
+
+	cases := []struct {
+		e      encode.Encoder
+		values interface{}
+	}{
+		{encode.I32{}, makeI32s(len(keys))},
+		{encode.String16{}, keys},
+		{encode.Int{}, ints},
+	}
+
+	for i, c := range cases {
+
+		st1, err := NewSlimTrie(c.e, keys, c.values, Opt{Complete: Bool(true)})
+		ta.NoError(err)
+
+		buf, err := st1.Marshal()
+		ta.NoError(err)
+
+		st2, err := Load(buf)
+		ta.NoError(err, "%d-th: encoder: %T", i+1, c.e)
+		ta.Equal(c.e, st2.encoder)
+		slimtrieEqual(st1, st2, t)
+
+		for j := 0; j < len(keys); j += 100 {
+			want, _ := st1.Get(keys[j])
+			v, found := st2.Get(keys[j])
+			ta.True(found)
+			ta.Equal(want, v)
+		}
+	}
+
+	// filter mode
+
+	st1, err := NewSlimTrie(nil, keys, nil)
+	ta.NoError(err)
+
+	buf, err := st1.Marshal()
+	ta.NoError(err)
+
+	st2, err := Load(buf)
+	ta.NoError(err)
+	ta.Nil(st2.encoder)
+	slimtrieEqual(st1, st2, t)
+
+	// an unregistered encoder is not recorded
+
+	te, err := encode.NewTypeEncoder(int32(0))
+	ta.NoError(err)
+
+	st1, err = NewSlimTrie(te, keys, makeI32s(len(keys)))
+	ta.NoError(err)
+
+	buf, err = st1.Marshal()
+	ta.NoError(err)
+
+	_, err = Load(buf)
+	ta.Equal(encode.ErrUnknownEncoder, errors.Cause(err))
+
+	st2, err = NewSlimTrie(te, nil, nil)
+	ta.NoError(err)
+	ta.NoError(st2.Unmarshal(buf))
+	slimtrieEqual(st1, st2, t)
+}
+
+func TestSlimTrie_Unmarshal_encoderMismatch(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("10vl5")
+
+	st1, err := NewSlimTrie(encode.I32{}, keys, makeI32s(len(keys)))
+	ta.NoError(err)
+
+	buf, err := st1.Marshal()
+	ta.NoError(err)
+
+	st2, err := NewSlimTrie(encode.U32{}, nil, nil)
+	ta.NoError(err)
+
+	err = st2.Unmarshal(buf)
+	ta.Equal(ErrEncoderMismatch, errors.Cause(err))
+
+	// an unknown encoder name
+
+	bad := append([]byte{}, buf...)
+	copy(bad[40:], "i32/9")
+	// checksum of the header with Checksum set to 0, and the body
+	binary.LittleEndian.PutUint32(bad[32:], 0)
+	binary.LittleEndian.PutUint32(bad[32:], crc32.Checksum(bad, crc32c))
+
+	_, err = Load(bad)
+	ta.Equal(encode.ErrUnknownEncoder, errors.Cause(err))
+
+	// an encoder that is not registered is not checked
+
+	te, err := encode.NewTypeEncoder(int32(0))
+	ta.NoError(err)
+
+	st2, err = NewSlimTrie(te, nil, nil)
+	ta.NoError(err)
+	ta.NoError(st2.Unmarshal(buf))
+}
@@ -0,0 +1,107 @@
+package trie
+
+import (
+	"bytes"
+	"reflect"
+
+	"github.com/openacid/errors"
+	"github.com/openacid/slim/encode"
+)
+
+// Merge merges several SlimTries into one, e.g., to compact sorted runs
+// without the original keys.
+// All SlimTries must be created with Opt.Complete, so that keys can be
+// rebuilt, otherwise it returns ErrNotComplete.
+// All SlimTries must have the same encoder, otherwise it returns
+// ErrEncoderMismatch.
+//
+// Leaves of all SlimTries are walked in key order and added to a Builder.
+// If a key is in more than one SlimTrie, resolve is called with the values of
+// it, in the order of "tries", and the returned value is stored.
+// The slice vals is reused and must not be retained by resolve.
+// If resolve is nil, the value in the last SlimTrie is stored.
+//
+// The created SlimTrie has the same options as tries[0], and keys in it are
+// also removed by Opt.DedupValue, if it is set.
+//
+// Since 0.5.11
+func Merge(tries []*SlimTrie, resolve func(key string, vals []interface{}) interface{}) (*SlimTrie, error) {
+
+	if len(tries) == 0 {
+		return NewSlimTrie(nil, nil, nil, Opt{Complete: Bool(true)})
+	}
+
+	e := tries[0].encoder
+
+	for i, st := range tries {
+		if !*st.Options().Complete {
+			return nil, errors.Wrapf(ErrNotComplete, "tries[%d]", i)
+		}
+
+		if !sameEncoder(e, st.encoder) {
+			return nil, errors.Wrapf(ErrEncoderMismatch,
+				"tries[0]: %T, tries[%d]: %T", e, i, st.encoder)
+		}
+	}
+
+	opt := tries[0].Options()
+	b := NewBuilder(e, opt)
+
+	its := make([]*Iterator, 0, len(tries))
+	for _, st := range tries {
+		it := st.Iter("", "")
+		if it.Next() {
+			its = append(its, it)
+		}
+	}
+
+	var vals []interface{}
+
+	for len(its) > 0 {
+
+		// the number of tries is usually small, a linear scan is good
+		// enough to find the smallest key.
+		// Keys are compared in the buffers of iterators, to avoid creating a
+		// string for every iterator by Iterator.Key().
+		smallest := its[0].key
+		for _, it := range its[1:] {
+			if bytes.Compare(it.key, smallest) < 0 {
+				smallest = it.key
+			}
+		}
+
+		// copy it before the buffer is updated by Next()
+		key := string(smallest)
+
+		vals = vals[:0]
+		remain := its[:0]
+
+		for _, it := range its {
+			if string(it.key) == key {
+				vals = append(vals, it.Value())
+				if !it.Next() {
+					continue
+				}
+			}
+			remain = append(remain, it)
+		}
+		its = remain
+
+		v := vals[len(vals)-1]
+		if len(vals) > 1 && resolve != nil {
+			v = resolve(key, vals)
+		}
+
+		if err := b.Add(key, v); err != nil {
+			return nil, err
+		}
+	}
+
+	return b.Build()
+}
+
+// sameEncoder checks if two encoders are of the same type and the same
+// parameters, if they are registered.
+func sameEncoder(a, b encode.Encoder) bool {
+	return reflect.TypeOf(a) == reflect.TypeOf(b) && encoderName(a) == encoderName(b)
+}
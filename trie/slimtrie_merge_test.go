@@ -0,0 +1,133 @@
+package trie
+
+import (
+	"math/bits"
+	"math/rand"
+	"testing"
+
+	"github.com/openacid/errors"
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerge(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+
+	// split keys into 3 runs, some keys are in more than one run.
+	runs := make([][]string, 3)
+	for _, k := range keys {
+		n := 0
+		for j := range runs {
+			if rand.Intn(2) == 0 {
+				runs[j] = append(runs[j], k)
+				n++
+			}
+		}
+		if n == 0 {
+			runs[0] = append(runs[0], k)
+		}
+	}
+
+	opt := Opt{Complete: Bool(true), DedupValue: Bool(false)}
+
+	// the value of a key in the j-th run is 1<<j
+	tries := make([]*SlimTrie, len(runs))
+	for j, ks := range runs {
+		vs := make([]int32, len(ks))
+		for i := range vs {
+			vs[i] = 1 << uint(j)
+		}
+
+		st, err := NewSlimTrie(encode.I32{}, ks, vs, opt)
+		ta.NoError(err)
+		tries[j] = st
+	}
+
+	st, err := Merge(tries, func(key string, vals []interface{}) interface{} {
+		s := int32(0)
+		for _, v := range vals {
+			s += v.(int32)
+		}
+		return s
+	})
+	ta.NoError(err)
+	ta.True(*st.Options().Complete)
+
+	want := make(map[string]int32, len(keys))
+	for j, ks := range runs {
+		for _, k := range ks {
+			want[k] += 1 << uint(j)
+		}
+	}
+
+	i := 0
+	st.Scan("", "", func(key string, value interface{}) bool {
+		ta.Equal(keys[i], key)
+		ta.Equal(want[key], value, "key: %q", key)
+		i++
+		return true
+	})
+	ta.Equal(len(keys), i)
+
+	// nil resolve stores the value in the last trie
+
+	st, err = Merge(tries, nil)
+	ta.NoError(err)
+
+	for _, k := range keys {
+		v, found := st.Get(k)
+		ta.True(found)
+		ta.Equal(int32(1)<<uint(bits.Len32(uint32(want[k]))-1), v, "key: %q", k)
+	}
+
+	// filter mode
+
+	filters := make([]*SlimTrie, len(runs))
+	for j, ks := range runs {
+		filters[j], err = NewSlimTrie(nil, ks, nil, opt)
+		ta.NoError(err)
+	}
+
+	st, err = Merge(filters, nil)
+	ta.NoError(err)
+
+	var got []string
+	st.Scan("", "", func(key string, value interface{}) bool {
+		got = append(got, key)
+		return true
+	})
+	ta.Equal(keys, got)
+}
+
+func TestMerge_error(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("10vl5")
+	values := makeI32s(len(keys))
+
+	complete, err := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	notComplete, err := NewSlimTrie(encode.I32{}, keys, values, Opt{InnerPrefix: Bool(true)})
+	ta.NoError(err)
+
+	_, err = Merge([]*SlimTrie{complete, notComplete}, nil)
+	ta.Equal(ErrNotComplete, errors.Cause(err))
+
+	i64s := make([]int64, len(keys))
+	otherEncoder, err := NewSlimTrie(encode.I64{}, keys, i64s, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	_, err = Merge([]*SlimTrie{complete, otherEncoder}, nil)
+	ta.Equal(ErrEncoderMismatch, errors.Cause(err))
+
+	// no trie
+
+	st, err := Merge(nil, nil)
+	ta.NoError(err)
+	ta.Equal(int32(-1), st.GetID(keys[0]))
+}
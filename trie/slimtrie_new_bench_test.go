@@ -1,7 +1,9 @@
 package trie
 
 import (
+	"runtime"
 	"testing"
+	"time"
 
 	"github.com/openacid/slim/encode"
 )
@@ -30,3 +32,117 @@ func BenchmarkNewSlimTrie_300(b *testing.B) {
 
 	Output = s
 }
+
+// BenchmarkNewSlimTrie_stream_200k_web2 and BenchmarkBuilder_stream_200k_web2
+// create a SlimTrie from a stream of keys, e.g., keys read from a file one by
+// one into a reused buffer.
+// With Builder there is no allocation for every key.
+//
+// Besides allocations they report "peak-heap-B/op", the highest heap size
+// sampled during creation, minus the heap size before it.
+// Builder still holds all keys before building, but in one buffer without a
+// string header for every key.
+
+func BenchmarkNewSlimTrie_stream_200k_web2(b *testing.B) {
+
+	src := getKeys("200kweb2")
+	buf := make([]byte, 0, 64)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	peak := uint64(0)
+
+	for i := 0; i < b.N; i++ {
+		peak += measurePeakHeap(func() {
+			keys := []string{}
+			values := []int32{}
+			for j, k := range src {
+				buf = append(buf[:0], k...)
+				keys = append(keys, string(buf))
+				values = append(values, int32(j))
+			}
+
+			st, err := NewSlimTrie(encode.I32{}, keys, values)
+			if err != nil {
+				panic(err)
+			}
+			Output += int(st.nodes.NodeTypeBM.Words[0])
+		})
+	}
+
+	b.ReportMetric(float64(peak)/float64(b.N), "peak-heap-B/op")
+}
+
+func BenchmarkBuilder_stream_200k_web2(b *testing.B) {
+
+	src := getKeys("200kweb2")
+	buf := make([]byte, 0, 64)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	peak := uint64(0)
+
+	for i := 0; i < b.N; i++ {
+		peak += measurePeakHeap(func() {
+			bld := NewBuilder(encode.I32{})
+			for j, k := range src {
+				buf = append(buf[:0], k...)
+				err := bld.Add(bytesToStr(buf), int32(j))
+				if err != nil {
+					panic(err)
+				}
+			}
+
+			st, err := bld.Build()
+			if err != nil {
+				panic(err)
+			}
+			Output += int(st.nodes.NodeTypeBM.Words[0])
+		})
+	}
+
+	b.ReportMetric(float64(peak)/float64(b.N), "peak-heap-B/op")
+}
+
+// measurePeakHeap runs fn and returns the highest heap size sampled while fn
+// is running, minus the heap size before fn.
+func measurePeakHeap(fn func()) uint64 {
+
+	var ms runtime.MemStats
+
+	runtime.GC()
+	runtime.ReadMemStats(&ms)
+	base := ms.HeapAlloc
+
+	stop := make(chan struct{})
+	done := make(chan uint64)
+
+	go func() {
+		var ms runtime.MemStats
+		peak := uint64(0)
+		for {
+			runtime.ReadMemStats(&ms)
+			if ms.HeapAlloc > peak {
+				peak = ms.HeapAlloc
+			}
+
+			select {
+			case <-stop:
+				done <- peak
+				return
+			case <-time.After(time.Millisecond):
+			}
+		}
+	}()
+
+	fn()
+
+	close(stop)
+	peak := <-done
+	if peak < base {
+		return 0
+	}
+	return peak - base
+}
@@ -0,0 +1,84 @@
+package trie
+
+import (
+	"os"
+
+	"github.com/openacid/errors"
+	"github.com/openacid/slim/encode"
+)
+
+// Open loads a SlimTrie from a file in aligned format, which is created by
+// writing the output of MarshalAligned() to a file.
+//
+// The file is memory mapped read-only, and the SlimTrie references the mapped
+// memory without copy.
+// Thus startup does not depend on the size of the file, and processes opening
+// the same file share one copy in page cache.
+// On a platform without mmap, the file is read into memory.
+//
+// Argument e must be the same encoder the SlimTrie is created with, otherwise
+// it returns ErrEncoderMismatch.
+// If e is nil, the encoder recorded in the file is used, see Load().
+// The SlimTrie must be closed with Close() to release the mapped memory, and
+// must not be used after Close().
+//
+// Since 0.5.11
+func Open(path string, e encode.Encoder) (*SlimTrie, error) {
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	size := fi.Size()
+	if size == 0 {
+		return nil, errors.Wrapf(ErrMalformed, "empty file: %s", path)
+	}
+	if int64(int(size)) != size {
+		return nil, errors.Errorf("file too large to map: %s, size: %d", path, size)
+	}
+
+	buf, err := mmap(f, int(size))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to map: %s", path)
+	}
+
+	st := &SlimTrie{encoder: e}
+	err = st.UnmarshalNoCopy(buf)
+	if err != nil {
+		munmap(buf)
+		return nil, err
+	}
+
+	if st.encoder == nil && st.nodes.Leaves != nil {
+		munmap(buf)
+		return nil, errors.Wrapf(encode.ErrUnknownEncoder, "no encoder name in file: %s", path)
+	}
+
+	st.mapped = buf
+	return st, nil
+}
+
+// Close releases the memory mapped by Open().
+// It does nothing for a SlimTrie not loaded by Open().
+//
+// Since 0.5.11
+func (st *SlimTrie) Close() error {
+
+	if st.mapped == nil {
+		return nil
+	}
+
+	st.nodes = &Nodes{}
+
+	buf := st.mapped
+	st.mapped = nil
+
+	return errors.WithStack(munmap(buf))
+}
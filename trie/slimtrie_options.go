@@ -0,0 +1,47 @@
+package trie
+
+// Options returns the normalized options a SlimTrie is created with.
+//
+// Complete is true if complete keys are stored, i.e., the SlimTrie is created
+// with Complete, or with both InnerPrefix and LeafPrefix.
+// In this case a key found by Get() is always present, and a caller does not
+// need to check it again.
+//
+// ValueGroup is always nil, since it can not be stored.
+// For a SlimTrie loaded from data created by an older version, InnerPrefix and
+// LeafPrefix are inferred from the stored data, and DedupValue is the default
+// true.
+//
+// Since 0.5.11
+func (st *SlimTrie) Options() Opt {
+
+	ns := st.nodes
+
+	bo := ns.BuildOptions
+	if bo == nil {
+		bo = &BuildOptions{DedupValue: true}
+		if ns.NodeTypeBM != nil {
+			bo.InnerPrefix = ns.InnerPrefixes != nil && ns.InnerPrefixes.PositionBM != nil
+			bo.LeafPrefix = ns.LeafPrefixes != nil
+		}
+	}
+
+	return Opt{
+		DedupValue:      Bool(bo.DedupValue),
+		InnerPrefix:     Bool(bo.InnerPrefix),
+		LeafPrefix:      Bool(bo.LeafPrefix),
+		Complete:        Bool(bo.Complete || bo.InnerPrefix && bo.LeafPrefix),
+		FingerprintBits: ns.FingerprintBits,
+		ValidateOnLoad:  Bool(st.validateOnLoad),
+	}
+}
+
+// newBuildOptions converts a normalized Opt to BuildOptions to store in Nodes.
+func newBuildOptions(o *Opt) *BuildOptions {
+	return &BuildOptions{
+		DedupValue:  *o.DedupValue,
+		InnerPrefix: *o.InnerPrefix,
+		LeafPrefix:  *o.LeafPrefix,
+		Complete:    o.Complete != nil && *o.Complete,
+	}
+}
@@ -0,0 +1,96 @@
+package trie
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_Options(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+
+	cases := []struct {
+		opt  Opt
+		want Opt
+	}{
+		{Opt{},
+			Opt{DedupValue: Bool(true), InnerPrefix: Bool(false), LeafPrefix: Bool(false), Complete: Bool(false)}},
+		{Opt{DedupValue: Bool(false), FingerprintBits: 8},
+			Opt{DedupValue: Bool(false), InnerPrefix: Bool(false), LeafPrefix: Bool(false), Complete: Bool(false), FingerprintBits: 8}},
+		{Opt{InnerPrefix: Bool(true)},
+			Opt{DedupValue: Bool(true), InnerPrefix: Bool(true), LeafPrefix: Bool(false), Complete: Bool(false)}},
+		{Opt{InnerPrefix: Bool(true), LeafPrefix: Bool(true)},
+			Opt{DedupValue: Bool(true), InnerPrefix: Bool(true), LeafPrefix: Bool(true), Complete: Bool(true)}},
+		{Opt{Complete: Bool(true), FingerprintBits: 8},
+			Opt{DedupValue: Bool(true), InnerPrefix: Bool(true), LeafPrefix: Bool(true), Complete: Bool(true)}},
+	}
+
+	for i, c := range cases {
+
+		c.want.ValidateOnLoad = Bool(false)
+
+		for _, ks := range [][]string{keys, nil} {
+
+			st, err := NewSlimTrie(encode.I32{}, ks, values[:len(ks)], c.opt)
+			ta.NoError(err)
+			ta.Equal(c.want, st.Options(), "%d-th: keys: %d", i+1, len(ks))
+
+			buf, err := st.Marshal()
+			ta.NoError(err)
+
+			st2, err := Load(buf)
+			ta.NoError(err)
+			ta.Equal(c.want, st2.Options(), "%d-th: keys: %d", i+1, len(ks))
+
+			buf, err = st.MarshalAligned()
+			ta.NoError(err)
+
+			st2, err = NewSlimTrie(encode.I32{}, nil, nil)
+			ta.NoError(err)
+			ta.NoError(st2.UnmarshalNoCopy(buf))
+			ta.Equal(c.want, st2.Options(), "%d-th: keys: %d", i+1, len(ks))
+		}
+	}
+
+	// options of loading
+
+	st, err := NewSlimTrie(encode.I32{}, nil, nil, Opt{ValidateOnLoad: Bool(true)})
+	ta.NoError(err)
+	ta.True(*st.Options().ValidateOnLoad)
+}
+
+func TestSlimTrie_Options_oldData(t *testing.T) {
+
+	ta := require.New(t)
+
+	b, err := ioutil.ReadFile("testdata/slimtrie-data-20kvl10-0.5.9")
+	ta.NoError(err)
+
+	st, err := NewSlimTrie(encode.I32{}, nil, nil)
+	ta.NoError(err)
+	ta.NoError(st.Unmarshal(b))
+
+	ta.Equal(Opt{
+		DedupValue:     Bool(true),
+		InnerPrefix:    Bool(false),
+		LeafPrefix:     Bool(false),
+		Complete:       Bool(false),
+		ValidateOnLoad: Bool(false),
+	}, st.Options())
+
+	// 0.5.10 data has no options stored
+
+	st1, err := NewSlimTrie(encode.I32{}, getKeys("10vl5"), makeI32s(10), Opt{Complete: Bool(true)})
+	ta.NoError(err)
+	st1.nodes.BuildOptions = nil
+
+	ta.True(*st1.Options().Complete)
+	ta.True(*st1.Options().InnerPrefix)
+	ta.True(*st1.Options().LeafPrefix)
+}
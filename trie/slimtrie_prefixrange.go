@@ -0,0 +1,137 @@
+package trie
+
+// PrefixRange returns the node ids of the first and the last leaf, in key
+// order, of keys that start with "prefix".
+// It returns -1, -1 if there is no such key.
+//
+// All keys with "prefix" are in one subtree, thus the first and the last leaf
+// are the left most and the right most leaf of it.
+// Leaves between them are visited by WithPrefix().
+//
+// With Opt.Complete the result is exact.
+// Otherwise a key with "prefix" is never missed, but SlimTrie does not store
+// enough info to check every byte of "prefix", thus the range may contain
+// keys without "prefix", or there may be a range when there is no key with
+// "prefix" at all.
+//
+// Since 0.5.11
+func (st *SlimTrie) PrefixRange(prefix string) (firstID, lastID int32) {
+
+	nid := st.prefixNode(prefix)
+	if nid == -1 {
+		return -1, -1
+	}
+
+	return st.leftMost(nid), st.rightMost(nid)
+}
+
+// WithPrefix calls fn for every key that starts with "prefix", in key order.
+// The key is "" if SlimTrie is not created with Opt.Complete.
+// It stops if fn returns false.
+//
+// False positives are the same as PrefixRange().
+//
+//	st.WithPrefix("user/123/", func(key string, v interface{}) bool {
+//		fmt.Println(key, v)
+//		return true
+//	})
+//
+// Since 0.5.11
+func (st *SlimTrie) WithPrefix(prefix string, fn func(key string, v interface{}) bool) {
+
+	it := &Iterator{
+		st: st,
+		qr: &querySession{},
+	}
+	it.resetPrefix(prefix)
+
+	for it.Next() {
+		if !fn(it.Key(), it.Value()) {
+			return
+		}
+	}
+}
+
+// prefixNode returns the id of the node whose subtree contains all keys
+// starting with "prefix", or -1 if there is no such key.
+func (st *SlimTrie) prefixNode(prefix string) int32 {
+
+	if st.nodes.NodeTypeBM == nil {
+		return -1
+	}
+
+	qr := &querySession{}
+
+	l := int32(8 * len(prefix))
+	qr.keyBitLen = l
+	qr.key = prefix
+
+	eqID, i := int32(0), int32(0)
+
+	for {
+
+		qr.isInner = false
+		qr.prefixLen = 0
+		qr.hasPrefixContent = false
+		qr.hasLeafPrefix = false
+
+		st.getInner(eqID, qr)
+		if !qr.isInner {
+			break
+		}
+
+		if i == l {
+			return eqID
+		}
+
+		if qr.hasPrefixContent {
+
+			end := i&^7 + qr.prefixLen
+			if end >= l {
+				// "prefix" ends inside the prefix of this node.
+				// The last byte of node prefix may be partial, but it is after
+				// the end of "prefix".
+				tail := prefix[i>>3:]
+				if tail != string(qr.prefix[1:1+len(tail)]) {
+					return -1
+				}
+				return eqID
+			}
+
+			if prefixCompare(prefix[i>>3:], qr.prefix) != 0 {
+				return -1
+			}
+			i = end
+		} else {
+			i += qr.prefixLen
+			if i >= l {
+				return eqID
+			}
+		}
+
+		lchID, has := st.getLEChildID(qr, i)
+		if has == 0 {
+			return -1
+		}
+		eqID = lchID + 1
+
+		i += qr.wordSize
+	}
+
+	// a leaf: its key has "prefix" if the rest of "prefix" is a prefix of the
+	// leaf prefix.
+
+	if st.nodes.LeafPrefixes != nil {
+		var lp []byte
+		if qr.hasLeafPrefix {
+			lp = qr.leafPrefix
+		}
+
+		tail := prefix[i>>3:]
+		if len(lp) < len(tail) || string(lp[:len(tail)]) != tail {
+			return -1
+		}
+	}
+
+	return eqID
+}
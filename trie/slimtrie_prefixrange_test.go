@@ -0,0 +1,169 @@
+package trie
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/openacid/slim/encode"
+	"github.com/openacid/testkeys"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_WithPrefix(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := []string{
+		"user/1",
+		"user/12/a",
+		"user/123/",
+		"user/123/a",
+		"user/123/b",
+		"user/124/a",
+		"users",
+	}
+	values := makeI32s(len(keys))
+
+	st, err := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	cases := []struct {
+		prefix string
+		want   []string
+	}{
+		{"", keys},
+		{"user/123/", []string{"user/123/", "user/123/a", "user/123/b"}},
+		{"user/123/a", []string{"user/123/a"}},
+		{"user/12", []string{"user/12/a", "user/123/", "user/123/a", "user/123/b", "user/124/a"}},
+		{"user/125", nil},
+		{"user/123/ab", nil},
+		{"users", []string{"users"}},
+		{"v", nil},
+	}
+
+	for i, c := range cases {
+
+		var got []string
+		st.WithPrefix(c.prefix, func(key string, v interface{}) bool {
+			got = append(got, key)
+			ta.Equal(values[indexOf(keys, key)], v)
+			return true
+		})
+		ta.Equal(c.want, got, "%d-th: case: %+v", i+1, c)
+	}
+
+	// stop
+
+	var got []string
+	st.WithPrefix("user/", func(key string, v interface{}) bool {
+		got = append(got, key)
+		return len(got) < 2
+	})
+	ta.Equal([]string{"user/1", "user/12/a"}, got)
+}
+
+func TestSlimTrie_PrefixRange(t *testing.T) {
+
+	ta := require.New(t)
+
+	st, err := NewSlimTrie(encode.I32{}, nil, nil)
+	ta.NoError(err)
+
+	first, last := st.PrefixRange("")
+	ta.Equal(int32(-1), first)
+	ta.Equal(int32(-1), last)
+
+	keys := []string{"a", "ab", "abc", "b"}
+	st, err = NewSlimTrie(encode.I32{}, keys, makeI32s(len(keys)), Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	first, last = st.PrefixRange("ab")
+	ta.Equal(int32(1), st.getLeaf(first))
+	ta.Equal(int32(2), st.getLeaf(last))
+
+	first, last = st.PrefixRange("b")
+	ta.Equal(first, last)
+	ta.Equal(int32(3), st.getLeaf(first))
+
+	first, last = st.PrefixRange("c")
+	ta.Equal(int32(-1), first)
+	ta.Equal(int32(-1), last)
+}
+
+func TestSlimTrie_WithPrefix_keys(t *testing.T) {
+
+	ta := require.New(t)
+
+	withPrefix := func(keys []string, prefix string) []string {
+		var rst []string
+		for _, k := range keys {
+			if strings.HasPrefix(k, prefix) {
+				rst = append(rst, k)
+			}
+		}
+		return rst
+	}
+
+	for _, typ := range testkeys.AssetNames() {
+
+		keys := getKeys(typ)
+		if len(keys) >= 1000 {
+			continue
+		}
+
+		values := makeI32s(len(keys))
+		valueOf := map[string]int32{}
+		for i, k := range keys {
+			valueOf[k] = values[i]
+		}
+
+		prefixes := makeAbsentKeys(keys, len(keys), 0, 5)
+		for _, k := range keys {
+			prefixes = append(prefixes, k[:len(k)/2], k, k+"x")
+			if len(k) > 0 {
+				prefixes = append(prefixes, k[:1], k[:len(k)-1])
+			}
+		}
+
+		for _, opt := range []Opt{{}, {InnerPrefix: Bool(true)}, {LeafPrefix: Bool(true)}, {Complete: Bool(true)}} {
+
+			st, err := NewSlimTrie(encode.I32{}, keys, values, opt)
+			ta.NoError(err)
+
+			for _, prefix := range prefixes {
+
+				want := withPrefix(keys, prefix)
+
+				got := map[int32]bool{}
+				st.WithPrefix(prefix, func(key string, v interface{}) bool {
+					got[v.(int32)] = true
+					return true
+				})
+
+				if opt.Complete != nil {
+					var gotKeys []string
+					st.WithPrefix(prefix, func(key string, v interface{}) bool {
+						gotKeys = append(gotKeys, key)
+						return true
+					})
+					ta.Equal(want, gotKeys, "keys: %s, prefix: %q", typ, prefix)
+					continue
+				}
+
+				// no false negative
+				for _, k := range want {
+					ta.True(got[valueOf[k]], "keys: %s, opt: %+v, prefix: %q", typ, opt, prefix)
+				}
+			}
+		}
+	}
+}
+
+func indexOf(keys []string, key string) int {
+	for i, k := range keys {
+		if k == key {
+			return i
+		}
+	}
+	return -1
+}
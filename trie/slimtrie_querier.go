@@ -0,0 +1,199 @@
+package trie
+
+// Querier holds buffers used by queries on a SlimTrie, so that they can be
+// reused by successive queries.
+//
+// Query methods on Querier do not allocate memory, except the first few calls
+// that grow the buffers.
+// Methods that return a decoded value in an interface{}, such as
+// SlimTrie.Get(), SlimTrie.RangeGet() and SlimTrie.LongestPrefix(), allocate
+// when boxing a value.
+// Methods on Querier return the encoded value in a []byte instead.
+// Type specific methods such as SlimTrie.GetI32() do not allocate either.
+// SlimTrie.MayHaveKeyInRange() does not allocate and has no Querier variant.
+//
+// A Querier must not be used by more than one goroutine at the same time.
+// Usually a Querier is held by a goroutine or pooled with sync.Pool:
+//
+//	q := st.NewQuerier()
+//	for _, k := range keys {
+//		v, found := q.GetValueBytes(k)
+//		...
+//	}
+//
+// Since 0.5.11
+type Querier struct {
+	st *SlimTrie
+	qr querySession
+
+	// walk is the path buffer for GetManyID.
+	walk []walkStep
+
+	// path is the path buffer for Count.
+	path []int32
+
+	it Iterator
+}
+
+// NewQuerier creates a Querier on this SlimTrie.
+//
+// Since 0.5.11
+func (st *SlimTrie) NewQuerier() *Querier {
+	q := &Querier{st: st}
+	q.it.st = st
+	q.it.qr = &q.qr
+	return q
+}
+
+// GetID is same as SlimTrie.GetID().
+//
+// Since 0.5.11
+func (q *Querier) GetID(key string) int32 {
+
+	if q.st.nodes.NodeTypeBM == nil {
+		return -1
+	}
+
+	q.qr = querySession{}
+	return q.st.getID(key, &q.qr, 0, 0, nil)
+}
+
+// GetIDBytes is same as SlimTrie.GetIDBytes().
+//
+// Since 0.5.11
+func (q *Querier) GetIDBytes(key []byte) int32 {
+	return q.GetID(bytesToStr(key))
+}
+
+// GetValueBytes is same as SlimTrie.GetValueBytes().
+//
+// Since 0.5.11
+func (q *Querier) GetValueBytes(key string) ([]byte, bool) {
+
+	eqID := q.GetID(key)
+	if eqID == -1 {
+		return nil, false
+	}
+
+	ith, _ := q.st.getLeafIndex(eqID)
+	return q.st.getIthLeafBytes(ith), true
+}
+
+// RangeGet is same as SlimTrie.RangeGet() except the value is returned in
+// encoded []byte.
+// The returned slice references the internal storage of SlimTrie and must not
+// be modified.
+//
+// Since 0.5.11
+func (q *Querier) RangeGet(key string) ([]byte, bool) {
+
+	id := q.st.rangeGetID(key)
+	if id == -1 {
+		return nil, false
+	}
+
+	return q.ValueBytesOf(id), true
+}
+
+// LongestPrefix is same as SlimTrie.LongestPrefix() except the value is
+// returned in encoded []byte.
+// The returned slice references the internal storage of SlimTrie and must not
+// be modified.
+//
+// Since 0.5.11
+func (q *Querier) LongestPrefix(key string) (matchedLen int, value []byte, found bool) {
+
+	id, n := q.st.longestPrefixID(key)
+	if id == -1 {
+		return 0, nil, false
+	}
+
+	return int(n), q.ValueBytesOf(id), true
+}
+
+// Rank is same as SlimTrie.Rank().
+//
+// Since 0.5.11
+func (q *Querier) Rank(key string) (int32, bool) {
+
+	eqID := q.GetID(key)
+	if eqID == -1 {
+		return -1, false
+	}
+
+	ith, _ := q.st.getLeafIndex(eqID)
+	return ith, true
+}
+
+// SearchID returns the leaf node ids of the greatest key < "key", of "key"
+// and of the smallest key > "key", just like SlimTrie.Search() does.
+// An id is -1 if there is no such key.
+// The value of a leaf is retrieved with ValueBytesOf().
+//
+// Since 0.5.11
+func (q *Querier) SearchID(key string) (lID, eqID, rID int32) {
+	return q.st.searchID(key)
+}
+
+// ValueBytesOf returns the encoded value of a leaf.
+// The returned slice references the internal storage of SlimTrie and must not
+// be modified.
+//
+// Since 0.5.11
+func (q *Querier) ValueBytesOf(nodeid int32) []byte {
+	ith, _ := q.st.getLeafIndex(nodeid)
+	return q.st.getIthLeafBytes(ith)
+}
+
+// GetManyID is same as SlimTrie.GetManyID().
+//
+// Since 0.5.11
+func (q *Querier) GetManyID(keys []string, ids []int32) {
+	q.walk = q.st.getManyID(keys, ids, q.walk)
+}
+
+// Count is same as SlimTrie.Count().
+//
+// Since 0.5.11
+func (q *Querier) Count(from, to string) int64 {
+	var n int64
+	n, q.path = q.st.count(from, to, q.path)
+	return n
+}
+
+// Scan is same as SlimTrie.Scan() except key and value are passed to fn in
+// []byte.
+// The key is nil if SlimTrie is not created with Opt.Complete.
+// Key and value are valid only in fn and must not be modified.
+//
+// Since 0.5.11
+func (q *Querier) Scan(from, to string, fn func(key, value []byte) bool) {
+	q.it.reset(from, to)
+	q.iterate(fn)
+}
+
+// WithPrefix is same as SlimTrie.WithPrefix() except key and value are passed
+// to fn in []byte, just like Scan().
+//
+// Since 0.5.11
+func (q *Querier) WithPrefix(prefix string, fn func(key, value []byte) bool) {
+	q.it.resetPrefix(prefix)
+	q.iterate(fn)
+}
+
+func (q *Querier) iterate(fn func(key, value []byte) bool) {
+
+	it := &q.it
+
+	for it.Next() {
+
+		var key []byte
+		if it.withKey {
+			key = it.key
+		}
+
+		if !fn(key, q.ValueBytesOf(it.nodeID)) {
+			return
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuerier(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+	queries := append(makeAbsentKeys(keys, 1000, 0, 20), keys[:1000]...)
+
+	for _, opt := range []Opt{{}, {InnerPrefix: Bool(true)}, {Complete: Bool(true)}} {
+
+		st, err := NewSlimTrie(encode.I32{}, keys, values, opt)
+		ta.NoError(err)
+
+		q := st.NewQuerier()
+
+		for _, k := range queries {
+
+			msg := []interface{}{"opt: %+v, key: %q", opt, k}
+
+			ta.Equal(st.GetID(k), q.GetID(k), msg...)
+			ta.Equal(st.GetID(k), q.GetIDBytes([]byte(k)), msg...)
+
+			v, found := st.GetValueBytes(k)
+			qv, qfound := q.GetValueBytes(k)
+			ta.Equal(v, qv, msg...)
+			ta.Equal(found, qfound, msg...)
+
+			l, eq, r := st.searchID(k)
+			ql, qeq, qr := q.SearchID(k)
+			ta.Equal([]int32{l, eq, r}, []int32{ql, qeq, qr}, msg...)
+			if eq != -1 {
+				_, v := encode.I32{}.Decode(q.ValueBytesOf(eq))
+				ta.Equal(st.getLeaf(eq), v, msg...)
+			}
+
+			ta.Equal(st.Count(k, keys[5000]), q.Count(k, keys[5000]), msg...)
+
+			v1, found := st.RangeGet(k)
+			qv, qfound = q.RangeGet(k)
+			ta.Equal(found, qfound, msg...)
+			if found {
+				_, dv := encode.I32{}.Decode(qv)
+				ta.Equal(v1, dv, msg...)
+			}
+
+			n, v1, found := st.LongestPrefix(k)
+			qn, qv, qfound := q.LongestPrefix(k)
+			ta.Equal(n, qn, msg...)
+			ta.Equal(found, qfound, msg...)
+			if found {
+				_, dv := encode.I32{}.Decode(qv)
+				ta.Equal(v1, dv, msg...)
+			}
+
+			rank, found := st.Rank(k)
+			qrank, qfound := q.Rank(k)
+			ta.Equal(rank, qrank, msg...)
+			ta.Equal(found, qfound, msg...)
+		}
+
+		ids := make([]int32, len(queries))
+		qids := make([]int32, len(queries))
+		st.GetManyID(queries, ids)
+		q.GetManyID(queries, qids)
+		ta.Equal(ids, qids)
+
+		// Scan and WithPrefix
+
+		var want, got []string
+		var wantVals, gotVals []interface{}
+
+		st.Scan(keys[100], keys[200], func(key string, v interface{}) bool {
+			want = append(want, key)
+			wantVals = append(wantVals, v)
+			return true
+		})
+		q.Scan(keys[100], keys[200], func(key, v []byte) bool {
+			if key == nil {
+				got = append(got, "")
+			} else {
+				got = append(got, string(key))
+			}
+			_, dv := encode.I32{}.Decode(v)
+			gotVals = append(gotVals, dv)
+			return true
+		})
+		ta.Equal(want, got, "opt: %+v", opt)
+		ta.Equal(wantVals, gotVals, "opt: %+v", opt)
+
+		want, got = nil, nil
+		st.WithPrefix(keys[100][:2], func(key string, v interface{}) bool {
+			want = append(want, key)
+			return true
+		})
+		q.WithPrefix(keys[100][:2], func(key, v []byte) bool {
+			if key == nil {
+				got = append(got, "")
+			} else {
+				got = append(got, string(key))
+			}
+			return true
+		})
+		ta.Equal(want, got, "opt: %+v", opt)
+	}
+}
+
+func TestQuerier_noAlloc(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+	queries := append(makeAbsentKeys(keys, 100, 0, 20), keys[:100]...)
+	bqueries := make([][]byte, len(queries))
+	for i, k := range queries {
+		bqueries[i] = []byte(k)
+	}
+
+	for _, opt := range []Opt{{}, {Complete: Bool(true)}} {
+
+		st, err := NewSlimTrie(encode.I32{}, keys, values, opt)
+		ta.NoError(err)
+
+		q := st.NewQuerier()
+		ids := make([]int32, len(queries))
+
+		nop := func(key, value []byte) bool { return true }
+
+		allocs := testing.AllocsPerRun(10, func() {
+			for i, k := range queries {
+				q.GetID(k)
+				q.GetIDBytes(bqueries[i])
+				q.GetValueBytes(k)
+				_, eqID, _ := q.SearchID(k)
+				if eqID != -1 {
+					q.ValueBytesOf(eqID)
+				}
+				q.Count(k, keys[1000])
+				q.RangeGet(k)
+				q.LongestPrefix(k)
+				q.Rank(k)
+				st.MayHaveKeyInRange(k, keys[1000])
+			}
+			q.GetManyID(queries, ids)
+			q.Scan(keys[10], keys[500], nop)
+			q.WithPrefix(keys[10][:2], nop)
+		})
+		ta.Equal(float64(0), allocs, "opt: %+v", opt)
+	}
+}
@@ -1,8 +1,8 @@
 package trie
 
 import (
-	"bytes"
 	"math/bits"
+	"sort"
 
 	"github.com/openacid/low/bitmap"
 	"github.com/openacid/low/bmtree"
@@ -75,23 +75,30 @@ func (st *SlimTrie) Get(key string) (interface{}, bool) {
 // Since 0.4.3
 func (st *SlimTrie) RangeGet(key string) (interface{}, bool) {
 
+	id := st.rangeGetID(key)
+	if id == -1 {
+		return nil, false
+	}
+
+	return st.getLeaf(id), true
+}
+
+// rangeGetID returns the leaf node id of the range that contains key, or -1.
+func (st *SlimTrie) rangeGetID(key string) int32 {
+
 	lID, eqID, _ := st.searchID(key)
 
 	// an "equal" match means key is a prefix of either start or end of a range.
 	if eqID != -1 {
 		// TODO eqID must be a leaf if it is not -1
-		return st.getLeaf(eqID), true
+		return eqID
 	}
 
-	// key is smaller than any range-start or range-end.
-	if lID == -1 {
-		return nil, false
-	}
-
-	// Preceding value is the start of this range.
+	// key is smaller than any range-start or range-end, lID is -1.
+	// Otherwise preceding value is the start of this range.
 	// It might be a false-positive
 
-	return st.getLeaf(lID), true
+	return lID
 }
 
 // Search for a key in SlimTrie.
@@ -128,19 +135,31 @@ func (st *SlimTrie) Search(key string) (lVal, eqVal, rVal interface{}) {
 // Since 0.5.10
 func (st *SlimTrie) GetID(key string) int32 {
 
-	eqID := int32(0)
-
 	if st.nodes.NodeTypeBM == nil {
 		return -1
 	}
 
-	l := int32(8 * len(key))
-	qr := &querySession{
-		keyBitLen: l,
-		key:       key,
-	}
+	qr := &querySession{}
 
-	i := int32(0)
+	return st.getID(key, qr, 0, 0, nil)
+}
+
+// walkStep is an inner node passed by a walk from root to a leaf, and the bit
+// position in key where this node starts.
+type walkStep struct {
+	nodeID int32
+	keyBit int32
+}
+
+// getID walks from node "eqID", which starts at the i-th bit of key, down to
+// the leaf of key.
+// If "path" is not nil, inner nodes it passes are appended to it.
+func (st *SlimTrie) getID(key string, qr *querySession, eqID, i int32, path *[]walkStep) int32 {
+
+	l := int32(8 * len(key))
+	qr.keyBitLen = l
+	qr.key = key
+	qr.hasLeafPrefix = false
 
 	for {
 
@@ -154,6 +173,10 @@ func (st *SlimTrie) GetID(key string) int32 {
 			break
 		}
 
+		if path != nil {
+			*path = append(*path, walkStep{eqID, i})
+		}
+
 		if qr.hasPrefixContent {
 			r := prefixCompare(key[i>>3:], qr.prefix)
 			if r != 0 {
@@ -189,20 +212,22 @@ func (st *SlimTrie) GetID(key string) int32 {
 		if i == l {
 			if qr.hasLeafPrefix {
 				return -1
-			} else {
-				return eqID
 			}
 		} else {
 			if !qr.hasLeafPrefix {
 				return -1
 			} else {
-				if !bytes.Equal(qr.leafPrefix, []byte(key[i>>3:])) {
+				if key[i>>3:] != string(qr.leafPrefix) {
 					return -1
 				}
 			}
 		}
 	}
 
+	if st.nodes.FingerprintBits > 0 && !st.matchFingerprint(eqID, key) {
+		return -1
+	}
+
 	return eqID
 }
 
@@ -215,7 +240,7 @@ func (st *SlimTrie) cmpLeafPrefix(tail string, qr *querySession) int32 {
 		} else {
 			leafPrefix = []byte{}
 		}
-		return int32(bytes.Compare([]byte(tail), leafPrefix))
+		return int32(cmpStrBytes(tail, leafPrefix))
 	}
 
 	return 0
@@ -543,18 +568,38 @@ func (st *SlimTrie) getLeaf(nodeid int32) interface{} {
 
 func (st *SlimTrie) getIthLeaf(ith int32) interface{} {
 
+	bs := st.getIthLeafBytes(ith)
+	if bs == nil {
+		return nil
+	}
+
+	_, v := st.encoder.Decode(bs)
+	return v
+}
+
+// getIthLeafBytes returns the encoded value of the ith leaf, or nil if there
+// is no value stored.
+func (st *SlimTrie) getIthLeafBytes(ith int32) []byte {
+
 	ls := st.nodes.Leaves
 	if ls == nil {
 		return nil
 	}
 
-	eltsize := st.encoder.GetEncodedSize(nil)
-	stIdx := ith * int32(eltsize)
+	if ls.PositionBM != nil {
+		// positions are shifted by index, see initLeaves()
+		from, to := ls.PositionBM.select32(ith)
+		return ls.Bytes[from-ith : to-ith-1]
+	}
 
-	bs := ls.Bytes[stIdx:]
+	// data created by older version does not have FixedSize set.
+	eltsize := ls.FixedSize
+	if eltsize == 0 {
+		eltsize = int32(st.encoder.GetEncodedSize(nil))
+	}
+	stIdx := ith * eltsize
 
-	_, v := st.encoder.Decode(bs)
-	return v
+	return ls.Bytes[stIdx : stIdx+eltsize]
 }
 
 func (st *SlimTrie) getLabels(qr *querySession) []uint64 {
@@ -568,3 +613,93 @@ func (st *SlimTrie) getLabels(qr *querySession) []uint64 {
 	bm := bitmap.Slice(ns.Inners.Words, qr.from, qr.to)
 	return bmtree.Decode(qr.to-qr.from, bm)
 }
+
+// innerCnt returns the number of inner nodes.
+func (st *SlimTrie) innerCnt() int32 {
+	ns := st.nodes
+	n := int32(len(ns.NodeTypeBM.Words))
+	if n == 0 {
+		return 0
+	}
+	r, bit := bitmap.Rank64(ns.NodeTypeBM.Words, ns.NodeTypeBM.RankIndex, n<<6-1)
+	return r + bit
+}
+
+// nodeCnt returns the number of all nodes.
+// Every node except the root is a child of some inner node, thus it is one
+// plus the number of "1" in Inners.
+func (st *SlimTrie) nodeCnt() int32 {
+	ns := st.nodes
+	if ns.NodeTypeBM == nil {
+		return 0
+	}
+	n := int32(len(ns.Inners.Words))
+	if n == 0 {
+		return 1
+	}
+	r, bit := bitmap.Rank128(ns.Inners.Words, ns.Inners.RankIndex, n<<6-1)
+	return r + bit + 1
+}
+
+// innerFrom returns the starting bit position in Inners of the ith inner node.
+func (st *SlimTrie) innerFrom(ithInner int32) int32 {
+
+	ns := st.nodes
+
+	if ithInner < ns.BigInnerCnt {
+		return ithInner * bigInnerSize
+	}
+
+	ithShort, _ := bitmap.Rank64(ns.ShortBM.Words, ns.ShortBM.RankIndex, ithInner)
+	return ns.BigInnerOffset + innerSize*ithInner + ns.ShortMinusInner*ithShort
+}
+
+// parentOf returns the id of the parent of a node.
+// It returns -1 for the root node.
+//
+// SlimTrie does not store parent, thus it is found by a "select" on Inners to
+// locate the bit of the node, and a binary search on inner nodes to find the
+// one whose bitmap contains this bit.
+func (st *SlimTrie) parentOf(nodeid int32) int32 {
+
+	if nodeid == 0 {
+		return -1
+	}
+
+	ns := st.nodes
+
+	// A child with id x is the x-th "1" in Inners, the root is not in it.
+	pos := selectR128(ns.Inners.Words, ns.Inners.RankIndex, nodeid-1)
+
+	ithInner := int32(sort.Search(int(st.innerCnt()), func(i int) bool {
+		return st.innerFrom(int32(i)) > pos
+	})) - 1
+
+	return selectR64(ns.NodeTypeBM.Words, ns.NodeTypeBM.RankIndex, ithInner)
+}
+
+// pathOf returns node ids from root to node "nodeid", inclusive.
+func (st *SlimTrie) pathOf(nodeid int32) []int32 {
+	return st.appendPath([]int32{}, nodeid)
+}
+
+// appendPath appends node ids from root to node "nodeid" to path.
+func (st *SlimTrie) appendPath(path []int32, nodeid int32) []int32 {
+
+	n := len(path)
+	for ; nodeid != -1; nodeid = st.parentOf(nodeid) {
+		path = append(path, nodeid)
+	}
+
+	for i, j := n, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// isComplete returns true if complete key content is stored, in which case a
+// key can be rebuilt by walking from root to a leaf.
+func (st *SlimTrie) isComplete() bool {
+	ns := st.nodes
+	return ns.LeafPrefixes != nil && ns.InnerPrefixes != nil && ns.InnerPrefixes.PositionBM != nil
+}
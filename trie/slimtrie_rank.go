@@ -0,0 +1,76 @@
+package trie
+
+import (
+	"sort"
+
+	"github.com/openacid/low/bitmap"
+)
+
+// Rank returns the dense ordinal of the leaf a key is mapped to, and a bool
+// indicating if the key is found.
+// The ordinal is in range [0, LeafCnt()) and is the same index of the value
+// of this key in Nodes.Leaves.
+//
+// The ordinal is NOT the position of a key in key order.
+// It is the order of leaves in breadth-first order.
+//
+// Rank() makes a SlimTrie a minimal-perfect-hash-like mapping:
+// By creating it without values(filter mode), every key has its own leaf,
+// thus a key can be mapped to a slot in an external array:
+//
+//	st, _ := NewSlimTrie(encode.Dummy{}, keys, nil)
+//	vals := make([]T, st.LeafCnt())
+//	ith, found := st.Rank(key)
+//	v := vals[ith]
+//
+// With Opt.DedupValue, a key with the same value as the previous key is not
+// stored, thus it does not have its own ordinal.
+//
+// Just like Get(), without Opt.Complete it could be a false positive for
+// an absent key.
+//
+// Since 0.5.11
+func (st *SlimTrie) Rank(key string) (int32, bool) {
+
+	eqID := st.GetID(key)
+	if eqID == -1 {
+		return -1, false
+	}
+
+	ith, _ := st.getLeafIndex(eqID)
+	return ith, true
+}
+
+// Select is the inverse of Rank().
+// It returns the node id of the leaf with ordinal "ith",
+// or -1 if "ith" is out of range.
+//
+// In Complete mode the key can be retrieved with KeyOf().
+//
+// Since 0.5.11
+func (st *SlimTrie) Select(ith int32) int32 {
+
+	if ith < 0 || ith >= st.LeafCnt() {
+		return -1
+	}
+
+	ns := st.nodes
+
+	// find the first node, before which there are more than ith leaves.
+	nid := sort.Search(int(st.nodeCnt()), func(i int) bool {
+		nInner, bit := bitmap.Rank64(ns.NodeTypeBM.Words, ns.NodeTypeBM.RankIndex, int32(i))
+		return int32(i)+1-nInner-bit > ith
+	})
+
+	return int32(nid)
+}
+
+// LeafCnt returns the number of leaves.
+//
+// Since 0.5.11
+func (st *SlimTrie) LeafCnt() int32 {
+	if st.nodes.NodeTypeBM == nil {
+		return 0
+	}
+	return st.nodeCnt() - st.innerCnt()
+}
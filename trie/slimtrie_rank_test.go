@@ -0,0 +1,89 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/openacid/slim/encode"
+	"github.com/openacid/testkeys"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_Rank_Select(t *testing.T) {
+
+	ta := require.New(t)
+
+	for _, typ := range testkeys.AssetNames() {
+
+		keys := getKeys(typ)
+		if len(keys) >= 1000 {
+			continue
+		}
+
+		for _, opt := range []Opt{{}, {Complete: Bool(true)}} {
+
+			st, err := NewSlimTrie(encode.Dummy{}, keys, nil, opt)
+			ta.NoError(err)
+
+			n := st.LeafCnt()
+			ta.Equal(int32(len(keys)), n, "keys: %s", typ)
+
+			seen := make([]bool, n)
+
+			for _, key := range keys {
+				ith, found := st.Rank(key)
+				ta.True(found, "keys: %s, key: %q", typ, key)
+				ta.True(ith >= 0 && ith < n, "keys: %s, key: %q", typ, key)
+				ta.False(seen[ith], "keys: %s, key: %q", typ, key)
+				seen[ith] = true
+
+				ta.Equal(st.GetID(key), st.Select(ith), "keys: %s, key: %q", typ, key)
+			}
+
+			ta.Equal(int32(-1), st.Select(-1))
+			ta.Equal(int32(-1), st.Select(n))
+		}
+	}
+}
+
+func TestSlimTrie_Rank_absent(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("300vl50")
+	values := makeI32s(len(keys))
+
+	st, err := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	for _, key := range makeAbsentKeys(keys, 1000, 0, 20) {
+		ith, found := st.Rank(key)
+		ta.False(found, "key: %q", key)
+		ta.Equal(int32(-1), ith)
+	}
+
+	for i, key := range keys {
+		ith, found := st.Rank(key)
+		ta.True(found)
+
+		v := st.getIthLeaf(ith)
+		ta.Equal(values[i], v)
+
+		got, found := st.KeyOf(st.Select(ith))
+		ta.True(found)
+		ta.Equal(key, got)
+	}
+}
+
+func TestSlimTrie_LeafCnt_empty(t *testing.T) {
+
+	ta := require.New(t)
+
+	st, err := NewSlimTrie(encode.I32{}, []string{}, []int32{})
+	ta.NoError(err)
+
+	ta.Equal(int32(0), st.LeafCnt())
+	ta.Equal(int32(-1), st.Select(0))
+
+	_, found := st.Rank("a")
+	ta.False(found)
+}
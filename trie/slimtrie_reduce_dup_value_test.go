@@ -78,3 +78,73 @@ func TestSlimTrie_Opt_DedupValue(t *testing.T) {
 		testPresentKeysGRS(t, st, keys, values)
 	}
 }
+
+func TestSlimTrie_Opt_ValueGroup(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := []string{
+		"Aaron",
+		"Agatha",
+		"Al",
+		"Albert",
+
+		"Alexander",
+		"Alison",
+	}
+	// disk offsets, grouped by 4KB block
+	values := []int64{
+		100, 200, 4000, 4095,
+		4096, 5000,
+	}
+	block := func(v interface{}) interface{} {
+		return v.(int64) &^ (4096 - 1)
+	}
+
+	opt := Opt{ValueGroup: block}
+
+	st, err := NewSlimTrie(encode.I64{}, keys, values, opt)
+	ta.NoError(err)
+
+	// same structure as deduped by exact values in TestSlimTrie_Opt_DedupValue
+	wantstr := trim(`
+#000+12*2
+    -0001->#001=100
+    -1100->#002
+               -0110->#003
+                          -0101->#004=4096
+`)
+	ta.Equal(wantstr, st.String())
+
+	// the exact value of the first key in a group is stored
+	for i, key := range keys {
+		v, found := st.RangeGet(key)
+		ta.True(found, "key: %s", key)
+		ta.Equal(values[(i/4)*4], v, "key: %s", key)
+	}
+
+	// Builder
+	b := NewBuilder(encode.I64{}, opt)
+	for i, k := range keys {
+		ta.NoError(b.Add(k, values[i]))
+	}
+	st2, err := b.Build()
+	ta.NoError(err)
+	slimtrieEqual(st, st2, t)
+
+	// ignored without DedupValue
+	st, err = NewSlimTrie(encode.I64{}, keys, values, Opt{ValueGroup: block, DedupValue: Bool(false)})
+	ta.NoError(err)
+	testPresentKeysGet64(t, st, keys, values)
+}
+
+func testPresentKeysGet64(t *testing.T, st *SlimTrie, keys []string, values []int64) {
+
+	ta := require.New(t)
+
+	for i, key := range keys {
+		v, found := st.Get(key)
+		ta.True(found, "key: %s", key)
+		ta.Equal(values[i], v, "key: %s", key)
+	}
+}
@@ -2,6 +2,7 @@ package trie
 
 import (
 	"fmt"
+	"math/rand"
 	"sort"
 	"strings"
 	"testing"
@@ -676,6 +677,102 @@ func TestSlimTrie_GRS_1_empty_string_branch(t *testing.T) {
 	testPresentKeysGRS(t, st, keys, values)
 }
 
+// rawString is a var-length encoder that stores a string as is, thus an empty
+// string is encoded to zero bytes.
+type rawString struct{}
+
+func (c rawString) Encode(d interface{}) []byte        { return []byte(d.(string)) }
+func (c rawString) Decode(b []byte) (int, interface{}) { return len(b), string(b) }
+func (c rawString) GetSize(d interface{}) int          { return len(d.(string)) }
+func (c rawString) GetEncodedSize(b []byte) int        { return len(b) }
+
+func TestSlimTrie_GRS_1_varLenValues(t *testing.T) {
+
+	ta := require.New(t)
+
+	t.Run("emptyValue", func(t *testing.T) {
+
+		ta := require.New(t)
+
+		keys := []string{"a", "b", "c", "d"}
+		values := []string{"xx", "", "yyy", "z"}
+
+		st, err := NewSlimTrie(rawString{}, keys, values)
+		ta.NoError(err)
+		ta.NotNil(st.nodes.Leaves.PositionBM)
+
+		for i, key := range keys {
+			v, found := st.Get(key)
+			ta.True(found, "key: %q", key)
+			ta.Equal(values[i], v, "key: %q", key)
+		}
+	})
+
+	opts := []Opt{
+		{},
+		{InnerPrefix: Bool(true)},
+		{Complete: Bool(true)},
+	}
+
+	for _, typ := range testkeys.AssetNames() {
+
+		keys := getKeys(typ)
+		if len(keys) >= 1000 {
+			continue
+		}
+
+		values := make([]string, len(keys))
+		for i, k := range keys {
+			values[i] = fmt.Sprintf("%d-%s", i, k[:len(k)/2])
+		}
+
+		for _, opt := range opts {
+
+			st, err := NewSlimTrie(encode.String16{}, keys, values, opt)
+			ta.NoError(err)
+
+			for i, key := range keys {
+				v, found := st.Get(key)
+				ta.True(found, "keys: %s, opt: %+v, key: %q", typ, opt, key)
+				ta.Equal(values[i], v, "keys: %s, opt: %+v, key: %q", typ, opt, key)
+
+				b, _ := st.GetValueBytes(key)
+				ta.Equal(encode.String16{}.Encode(values[i]), b)
+			}
+
+			gotVals := []string{}
+			st.Scan("", "", func(key string, v interface{}) bool {
+				gotVals = append(gotVals, v.(string))
+				return true
+			})
+			ta.Equal(values, gotVals, "keys: %s, opt: %+v", typ, opt)
+		}
+	}
+}
+
+func TestSlimTrie_GRS_1_varLenValues_equalSize(t *testing.T) {
+
+	// var-length encoder with all values of the same size is stored as fixed
+	// size values.
+
+	ta := require.New(t)
+
+	keys := []string{"a", "b", "c"}
+	values := []string{"x", "y", "z"}
+
+	st, err := NewSlimTrie(encode.String16{}, keys, values)
+	ta.NoError(err)
+
+	ta.Nil(st.nodes.Leaves.PositionBM)
+	ta.Equal(int32(3), st.nodes.Leaves.FixedSize)
+
+	for i, key := range keys {
+		v, found := st.Get(key)
+		ta.True(found)
+		ta.Equal(values[i], v)
+	}
+}
+
 func TestSlimTrie_Search_0_tiny(t *testing.T) {
 
 	ta := require.New(t)
@@ -767,6 +864,117 @@ func TestSlimTrie_Search_0_tiny(t *testing.T) {
 	}
 }
 
+func TestSlimTrie_MayHaveKeyInRange_tiny(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := []string{
+		"abc",
+		"abcd",
+		"abd",
+		"abde",
+		"bc",
+		"bcd",
+		"bcde",
+		"cde",
+	}
+	values := makeI32s(len(keys))
+
+	cases := []struct {
+		from, to string
+		want     bool
+	}{
+		{"", "", true},
+		{"", "a", false},
+		{"", "abc", false},
+		{"", "abc\x00", true},
+		{"ab", "abc", false},
+		{"abc", "abc", false},
+		{"abc", "abb", false},
+		{"abca", "abcd", false},
+		{"abcda", "abd", false},
+		{"abcd", "abd", true},
+		{"abdf", "bc", false},
+		{"abdf", "bca", true},
+		{"bcdf", "cde", false},
+		{"bcdf", "", true},
+		{"cdf", "", false},
+		{"d", "e", false},
+	}
+
+	for _, opt := range []Opt{{}, {InnerPrefix: Bool(true)}, {Complete: Bool(true)}} {
+
+		st, err := NewSlimTrie(encode.I32{}, keys, values, opt)
+		ta.NoError(err)
+
+		complete := *st.Options().Complete
+
+		for i, c := range cases {
+			got := st.MayHaveKeyInRange(c.from, c.to)
+			if complete {
+				ta.Equal(c.want, got, "%d-th: case: %+v", i+1, c)
+			} else if c.want {
+				ta.True(got, "%d-th: case: %+v", i+1, c)
+			}
+		}
+	}
+
+	st, err := NewSlimTrie(encode.I32{}, nil, nil)
+	ta.NoError(err)
+	ta.False(st.MayHaveKeyInRange("", ""))
+}
+
+func TestSlimTrie_MayHaveKeyInRange(t *testing.T) {
+
+	ta := require.New(t)
+
+	opts := []Opt{
+		{},
+		{InnerPrefix: Bool(true)},
+		{LeafPrefix: Bool(true)},
+		{FingerprintBits: 8},
+		{Complete: Bool(true)},
+	}
+
+	for _, typ := range testkeys.AssetNames() {
+
+		keys := getKeys(typ)
+		if len(keys) == 0 || len(keys) > 20000 {
+			continue
+		}
+		values := makeI32s(len(keys))
+
+		bounds := append(makeAbsentKeys(keys, 1000, 0, 20), "")
+		for i := 0; i < 1000; i++ {
+			k := keys[rand.Intn(len(keys))]
+			bounds = append(bounds, k, k[:rand.Intn(len(k)+1)])
+		}
+
+		for _, opt := range opts {
+
+			st, err := NewSlimTrie(encode.I32{}, keys, values, opt)
+			ta.NoError(err)
+
+			complete := *st.Options().Complete
+
+			for i := 0; i < 2000; i++ {
+				from := bounds[rand.Intn(len(bounds))]
+				to := bounds[rand.Intn(len(bounds))]
+
+				j := sort.SearchStrings(keys, from)
+				want := j < len(keys) && (to == "" || keys[j] < to)
+
+				got := st.MayHaveKeyInRange(from, to)
+				if complete {
+					ta.Equal(want, got, "keys: %s, from: %q, to: %q", typ, from, to)
+				} else if want {
+					ta.True(got, "keys: %s, opt: %+v, from: %q, to: %q", typ, opt, from, to)
+				}
+			}
+		}
+	}
+}
+
 func slimtrieEqual(st1, st2 *SlimTrie, t *testing.T) {
 	if !proto.Equal((st1.nodes), (st2.nodes)) {
 		fmt.Println(st1)
@@ -0,0 +1,131 @@
+package trie
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/openacid/errors"
+	"github.com/openacid/must"
+	"github.com/openacid/slim/encode"
+)
+
+// DupPolicy decides the value of a key that presents more than once when
+// creating a SlimTrie with NewSlimTrieUnsorted().
+// vals are values of the key in the order they are in the input.
+// In filter mode, i.e., values is nil, vals are all nil.
+//
+// A user defined DupPolicy combines values into one, and the returned value
+// must be of the same type as the input values.
+//
+// Since 0.5.11
+type DupPolicy func(key string, vals []interface{}) (interface{}, error)
+
+// DupKeepFirst is a DupPolicy that uses the first value of a duplicate key.
+//
+// Since 0.5.11
+func DupKeepFirst(key string, vals []interface{}) (interface{}, error) {
+	return vals[0], nil
+}
+
+// DupKeepLast is a DupPolicy that uses the last value of a duplicate key.
+//
+// Since 0.5.11
+func DupKeepLast(key string, vals []interface{}) (interface{}, error) {
+	return vals[len(vals)-1], nil
+}
+
+// DupError is a DupPolicy that returns ErrDuplicateKey.
+//
+// Since 0.5.11
+func DupError(key string, vals []interface{}) (interface{}, error) {
+	return nil, errors.Wrapf(ErrDuplicateKey, "key: %q presents %d times", key, len(vals))
+}
+
+// NewSlimTrieUnsorted is same as NewSlimTrie() except keys do not need to be
+// sorted and may have duplicates.
+// Keys are sorted along with values, and the value of a duplicate key is
+// decided by dup.
+// Arguments keys and values are not modified.
+//
+// Since 0.5.11
+func NewSlimTrieUnsorted(e encode.Encoder, keys []string, values interface{}, dup DupPolicy, opts ...Opt) (*SlimTrie, error) {
+
+	n := len(keys)
+
+	var rvals reflect.Value
+	if values != nil {
+		rvals = reflect.ValueOf(values)
+
+		must.Be.OK(func() {
+			must.Be.Equal(reflect.Slice, rvals.Kind(),
+				"values must be slice")
+
+			must.Be.Equal(n, rvals.Len(),
+				"len(keys) must equal len(values)")
+		})
+	}
+
+	idx := make([]int32, n)
+	for i := range idx {
+		idx[i] = int32(i)
+	}
+
+	// stable sort keeps duplicates in input order.
+	sort.SliceStable(idx, func(i, j int) bool {
+		return keys[idx[i]] < keys[idx[j]]
+	})
+
+	sortedKeys := make([]string, 0, n)
+	var sortedVals reflect.Value
+	if values != nil {
+		sortedVals = reflect.MakeSlice(rvals.Type(), 0, n)
+	}
+
+	for i := 0; i < n; {
+
+		key := keys[idx[i]]
+
+		j := i + 1
+		for j < n && keys[idx[j]] == key {
+			j++
+		}
+
+		sortedKeys = append(sortedKeys, key)
+
+		if j-i == 1 {
+			if values != nil {
+				sortedVals = reflect.Append(sortedVals, rvals.Index(int(idx[i])))
+			}
+			i = j
+			continue
+		}
+
+		vals := make([]interface{}, j-i)
+		if values != nil {
+			for k := i; k < j; k++ {
+				vals[k-i] = rvals.Index(int(idx[k])).Interface()
+			}
+		}
+
+		v, err := dup(key, vals)
+		if err != nil {
+			return nil, err
+		}
+
+		if values != nil {
+			rv := reflect.Zero(rvals.Type().Elem())
+			if v != nil {
+				rv = reflect.ValueOf(v)
+			}
+			sortedVals = reflect.Append(sortedVals, rv)
+		}
+
+		i = j
+	}
+
+	if values == nil {
+		return NewSlimTrie(e, sortedKeys, nil, opts...)
+	}
+
+	return NewSlimTrie(e, sortedKeys, sortedVals.Interface(), opts...)
+}
@@ -0,0 +1,94 @@
+package trie
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/openacid/errors"
+	"github.com/openacid/slim/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSlimTrieUnsorted(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+
+	want, err := NewSlimTrie(encode.I32{}, keys, values, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+
+	shuffledKeys := append([]string{}, keys...)
+	shuffledVals := append([]int32{}, values...)
+	rand.Shuffle(len(keys), func(i, j int) {
+		shuffledKeys[i], shuffledKeys[j] = shuffledKeys[j], shuffledKeys[i]
+		shuffledVals[i], shuffledVals[j] = shuffledVals[j], shuffledVals[i]
+	})
+
+	inputKeys := append([]string{}, shuffledKeys...)
+
+	st, err := NewSlimTrieUnsorted(encode.I32{}, shuffledKeys, shuffledVals, DupError, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+	slimtrieEqual(want, st, t)
+
+	// input is not modified
+	ta.Equal(inputKeys, shuffledKeys)
+
+	// filter mode
+
+	want, err = NewSlimTrie(nil, keys, nil)
+	ta.NoError(err)
+
+	st, err = NewSlimTrieUnsorted(nil, shuffledKeys, nil, DupError)
+	ta.NoError(err)
+	slimtrieEqual(want, st, t)
+}
+
+func TestNewSlimTrieUnsorted_dup(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := []string{"b", "a", "c", "a", "b", "a"}
+	values := []int32{1, 2, 3, 4, 5, 6}
+
+	sum := func(key string, vals []interface{}) (interface{}, error) {
+		s := int32(0)
+		for _, v := range vals {
+			s += v.(int32)
+		}
+		return s, nil
+	}
+
+	cases := []struct {
+		dup  DupPolicy
+		want []int32
+	}{
+		{DupKeepFirst, []int32{2, 1, 3}},
+		{DupKeepLast, []int32{6, 5, 3}},
+		{sum, []int32{12, 6, 3}},
+	}
+
+	for i, c := range cases {
+
+		st, err := NewSlimTrieUnsorted(encode.I32{}, keys, values, c.dup, Opt{Complete: Bool(true)})
+		ta.NoError(err)
+
+		for j, k := range []string{"a", "b", "c"} {
+			v, found := st.Get(k)
+			ta.True(found, "%d-th: key: %s", i+1, k)
+			ta.Equal(c.want[j], v, "%d-th: key: %s", i+1, k)
+		}
+	}
+
+	_, err := NewSlimTrieUnsorted(encode.I32{}, keys, values, DupError)
+	ta.Equal(ErrDuplicateKey, errors.Cause(err))
+	ta.NotEqual(ErrKeyOutOfOrder, errors.Cause(err))
+
+	_, err = NewSlimTrieUnsorted(nil, keys, nil, DupError)
+	ta.Equal(ErrDuplicateKey, errors.Cause(err))
+
+	st, err := NewSlimTrieUnsorted(nil, keys, nil, DupKeepLast, Opt{Complete: Bool(true)})
+	ta.NoError(err)
+	ta.Equal([]string{"a", "b", "c"}, st.Keys())
+}
@@ -0,0 +1,437 @@
+package trie
+
+import (
+	"fmt"
+	"math/bits"
+
+	"github.com/openacid/errors"
+	"github.com/openacid/low/bitmap"
+)
+
+// Validate checks the internal structure of a SlimTrie, such as the sizes of
+// bitmaps and the consistency of their indexes.
+//
+// A SlimTrie created by NewSlimTrie() is always valid.
+// Validate is meant for a SlimTrie loaded from untrusted or possibly corrupted
+// data, with which a query may panic or return garbage.
+// It does not check that the keys are stored in order, which can not be
+// checked without the original keys.
+//
+// It returns an error wrapping ErrMalformed if a problem is found.
+// See Opt.ValidateOnLoad to validate a SlimTrie when it is loaded.
+//
+// Since 0.5.11
+func (st *SlimTrie) Validate() error {
+
+	ns := st.nodes
+
+	// an empty SlimTrie.
+	if ns == nil || ns.NodeTypeBM == nil {
+		return nil
+	}
+
+	for _, b := range []struct {
+		name string
+		bm   *Bitmap
+		typ  string
+	}{
+		{"NodeTypeBM", ns.NodeTypeBM, "r64"},
+		{"Inners", ns.Inners, "r128"},
+		{"ShortBM", ns.ShortBM, "r64"},
+	} {
+		if err := validateBitmap(b.name, b.bm, b.typ); err != nil {
+			return err
+		}
+	}
+
+	nodeCnt := 1 + onesCount(ns.Inners.Words)
+	innerCnt := onesCount(ns.NodeTypeBM.Words)
+	leafCnt := nodeCnt - innerCnt
+
+	if bitCap(ns.NodeTypeBM.Words) < nodeCnt || highestBit(ns.NodeTypeBM.Words) >= nodeCnt {
+		return malformedf("NodeTypeBM: %d bits, %d nodes", bitCap(ns.NodeTypeBM.Words), nodeCnt)
+	}
+
+	if err := st.validateShape(innerCnt); err != nil {
+		return err
+	}
+
+	if err := st.validateInners(innerCnt); err != nil {
+		return err
+	}
+
+	if err := st.validateInnerPrefixes(innerCnt); err != nil {
+		return err
+	}
+
+	if err := st.validateLeafPrefixes(leafCnt); err != nil {
+		return err
+	}
+
+	if err := st.validateLeaves(leafCnt); err != nil {
+		return err
+	}
+
+	return st.validateFingerprints(leafCnt)
+}
+
+// validateShape checks the fields describing big, normal and short inner nodes.
+func (st *SlimTrie) validateShape(innerCnt int32) error {
+
+	ns := st.nodes
+
+	if ns.BigInnerCnt < 0 || ns.BigInnerCnt > innerCnt {
+		return malformedf("BigInnerCnt: %d, inner nodes: %d", ns.BigInnerCnt, innerCnt)
+	}
+
+	if ns.BigInnerOffset != (bigInnerSize-innerSize)*ns.BigInnerCnt {
+		return malformedf("BigInnerOffset: %d, BigInnerCnt: %d", ns.BigInnerOffset, ns.BigInnerCnt)
+	}
+
+	if ns.ShortSize < 0 || ns.ShortSize > maxShortSize {
+		return malformedf("ShortSize: %d", ns.ShortSize)
+	}
+
+	if ns.ShortMinusInner != ns.ShortSize-innerSize {
+		return malformedf("ShortMinusInner: %d, ShortSize: %d", ns.ShortMinusInner, ns.ShortSize)
+	}
+
+	if ns.ShortMask != bitmap.Mask[ns.ShortSize] {
+		return malformedf("ShortMask: %x, ShortSize: %d", ns.ShortMask, ns.ShortSize)
+	}
+
+	if len(ns.ShortTable) != 1<<uint(ns.ShortSize) {
+		return malformedf("ShortTable: %d elts, ShortSize: %d", len(ns.ShortTable), ns.ShortSize)
+	}
+
+	// A short bitmap must be mapped to a 17-bit bitmap with the same number
+	// of "1", thus rank on Inners still works.
+	for short, bm := range ns.ShortTable {
+		if bm == 0 {
+			continue
+		}
+		if bm>>uint(innerSize) != 0 || bits.OnesCount32(bm) != bits.OnesCount32(uint32(short)) {
+			return malformedf("ShortTable[%d]: %x", short, bm)
+		}
+	}
+
+	sbm := ns.ShortBM.Words
+	if onesCount(sbm) > 0 {
+		if highestBit(sbm) >= innerCnt || lowestBit(sbm) < ns.BigInnerCnt {
+			return malformedf("ShortBM: short nodes out of [%d, %d)", ns.BigInnerCnt, innerCnt)
+		}
+	}
+
+	if innerCnt > ns.BigInnerCnt && bitCap(sbm) < innerCnt {
+		return malformedf("ShortBM: %d bits, inner nodes: %d", bitCap(sbm), innerCnt)
+	}
+
+	return nil
+}
+
+// validateInners checks that Inners is large enough for all inner nodes, and
+// every inner node has at least one child with a greater node id.
+func (st *SlimTrie) validateInners(innerCnt int32) error {
+
+	ns := st.nodes
+	inn := ns.Inners
+
+	shortCnt := onesCount(ns.ShortBM.Words)
+	total := bigInnerSize*ns.BigInnerCnt + innerSize*(innerCnt-ns.BigInnerCnt) + ns.ShortMinusInner*shortCnt
+
+	if bitCap(inn.Words) < total || highestBit(inn.Words) >= total {
+		return malformedf("Inners: %d bits, %d bits of inner nodes", bitCap(inn.Words), total)
+	}
+
+	if innerCnt > 0 && ns.NodeTypeBM.Words[0]&1 == 0 {
+		return malformedf("root is not an inner node")
+	}
+
+	ithInner := int32(0)
+
+	for wordI, w := range ns.NodeTypeBM.Words {
+		for ; w != 0; w &= w - 1 {
+
+			nodeid := int32(wordI<<6 + bits.TrailingZeros64(w))
+
+			from := st.innerFrom(ithInner)
+			to := from + innerSize
+			isShort := false
+			if ithInner < ns.BigInnerCnt {
+				to = from + bigInnerSize
+			} else if ns.ShortBM.Words[ithInner>>6]&bitmap.Bit[ithInner&63] != 0 {
+				to = from + ns.ShortSize
+				isShort = true
+			}
+
+			r0, _ := bitmap.Rank128(inn.Words, inn.RankIndex, from)
+			r1, bit := bitmap.Rank128(inn.Words, inn.RankIndex, to-1)
+			childCnt := r1 + bit - r0
+
+			if childCnt == 0 {
+				return malformedf("inner node %d has no child", nodeid)
+			}
+
+			if isShort {
+				short := getBits(inn.Words, from, ns.ShortSize)
+				if ns.ShortTable[short] == 0 {
+					return malformedf("inner node %d: short bitmap %x not in ShortTable", nodeid, short)
+				}
+			}
+
+			// nodes are numbered in breadth-first order, a child always has a
+			// greater id than its parent.
+			if r0+1 <= nodeid {
+				return malformedf("inner node %d: first child: %d", nodeid, r0+1)
+			}
+
+			ithInner++
+		}
+	}
+
+	return nil
+}
+
+// validateInnerPrefixes checks prefixes or steps of inner nodes.
+func (st *SlimTrie) validateInnerPrefixes(innerCnt int32) error {
+
+	ps := st.nodes.InnerPrefixes
+	if ps == nil {
+		return malformedf("InnerPrefixes: nil")
+	}
+
+	if err := validateBitmap("InnerPrefixes.PresenceBM", ps.PresenceBM, "r128"); err != nil {
+		return err
+	}
+
+	words := ps.PresenceBM.Words
+	eltCnt := onesCount(words)
+
+	if ps.EltCnt != eltCnt {
+		return malformedf("InnerPrefixes: EltCnt: %d, PresenceBM has %d", ps.EltCnt, eltCnt)
+	}
+
+	if eltCnt > 0 && (bitCap(words) < innerCnt || highestBit(words) >= innerCnt) {
+		return malformedf("InnerPrefixes.PresenceBM: %d bits, inner nodes: %d", bitCap(words), innerCnt)
+	}
+
+	if ps.PositionBM == nil {
+		if ps.FixedSize != 2 || int32(len(ps.Bytes)) != 2*eltCnt {
+			return malformedf("InnerPrefixes: FixedSize: %d, %d bytes of %d steps",
+				ps.FixedSize, len(ps.Bytes), eltCnt)
+		}
+		return nil
+	}
+
+	err := validatePositions("InnerPrefixes", ps.PositionBM, eltCnt, int32(len(ps.Bytes)))
+	if err != nil {
+		return err
+	}
+
+	// A prefix starts with a control byte and, if the lowest bit of the
+	// control byte is set, ends with a byte with a trailing "1" bit.
+	from := int32(0)
+	for i := int32(0); i < eltCnt; i++ {
+
+		_, to := ps.PositionBM.select32(i)
+		pref := ps.Bytes[from:to]
+		from = to
+
+		if pref[0]&1 != 0 && (len(pref) < 2 || pref[len(pref)-1] == 0) {
+			return malformedf("InnerPrefixes: %d-th prefix: %x", i, pref)
+		}
+	}
+
+	return nil
+}
+
+// validateLeafPrefixes checks prefixes of leaves, if there are.
+func (st *SlimTrie) validateLeafPrefixes(leafCnt int32) error {
+
+	ps := st.nodes.LeafPrefixes
+	if ps == nil {
+		return nil
+	}
+
+	if err := validateBitmap("LeafPrefixes.PresenceBM", ps.PresenceBM, "r64"); err != nil {
+		return err
+	}
+
+	words := ps.PresenceBM.Words
+	if bitCap(words) < leafCnt || highestBit(words) >= leafCnt {
+		return malformedf("LeafPrefixes.PresenceBM: %d bits, leaves: %d", bitCap(words), leafCnt)
+	}
+
+	return validatePositions("LeafPrefixes", ps.PositionBM, onesCount(words), int32(len(ps.Bytes)))
+}
+
+// validateLeaves checks that Leaves has exactly one value for every leaf.
+func (st *SlimTrie) validateLeaves(leafCnt int32) error {
+
+	ls := st.nodes.Leaves
+	if ls == nil {
+		return nil
+	}
+
+	if ls.PositionBM != nil {
+		// positions are shifted by index, see initLeaves()
+		err := validatePositions("Leaves", ls.PositionBM, leafCnt, int32(len(ls.Bytes))+leafCnt)
+		if err != nil {
+			return err
+		}
+	} else {
+
+		// data created by older version does not have FixedSize set.
+		eltsize := ls.FixedSize
+		if eltsize == 0 {
+			if st.encoder == nil {
+				return malformedf("Leaves: unknown value size without encoder")
+			}
+			n, err := encodedSize(st, nil)
+			if err != nil {
+				return err
+			}
+			eltsize = int32(n)
+		}
+
+		if eltsize < 0 || int64(len(ls.Bytes)) != int64(eltsize)*int64(leafCnt) {
+			return malformedf("Leaves: %d bytes, value size: %d, leaves: %d", len(ls.Bytes), eltsize, leafCnt)
+		}
+	}
+
+	if st.encoder == nil {
+		return nil
+	}
+
+	for i := int32(0); i < leafCnt; i++ {
+		bs := st.getIthLeafBytes(i)
+		n, err := encodedSize(st, bs)
+		if err != nil {
+			return errors.WithMessage(err, fmt.Sprintf("%d-th leaf", i))
+		}
+		if n != len(bs) {
+			return malformedf("%d-th leaf: %d bytes, encoded size: %d", i, len(bs), n)
+		}
+	}
+
+	return nil
+}
+
+// validateFingerprints checks that there is a fingerprint for every leaf.
+func (st *SlimTrie) validateFingerprints(leafCnt int32) error {
+
+	ns := st.nodes
+
+	if ns.FingerprintBits < 0 || ns.FingerprintBits > 64 {
+		return malformedf("FingerprintBits: %d", ns.FingerprintBits)
+	}
+
+	if ns.FingerprintBits == 0 {
+		return nil
+	}
+
+	if ns.Fingerprints == nil || int64(bitCap(ns.Fingerprints.Words)) < int64(leafCnt)*int64(ns.FingerprintBits) {
+		return malformedf("Fingerprints: too few for %d leaves", leafCnt)
+	}
+
+	return nil
+}
+
+// validateBitmap checks that the index of a bitmap is the same as the one
+// built by indexit(typ).
+func validateBitmap(name string, bm *Bitmap, typ string) error {
+
+	if bm == nil {
+		return malformedf("%s: nil", name)
+	}
+
+	want := &Bitmap{Words: bm.Words}
+	want.indexit(typ)
+
+	if !equalI32s(bm.RankIndex, want.RankIndex) {
+		return malformedf("%s: RankIndex does not match", name)
+	}
+
+	if typ == "s32" && !equalI32s(bm.SelectIndex, want.SelectIndex) {
+		return malformedf("%s: SelectIndex does not match", name)
+	}
+
+	return nil
+}
+
+// validatePositions checks a PositionBM that splits size bytes into n
+// elements.
+func validatePositions(name string, bm *Bitmap, n, size int32) error {
+
+	if err := validateBitmap(name+".PositionBM", bm, "s32"); err != nil {
+		return err
+	}
+
+	if onesCount(bm.Words) != n+1 || lowestBit(bm.Words) != 0 || highestBit(bm.Words) != size {
+		return malformedf("%s.PositionBM: does not split %d bytes into %d elts", name, size, n)
+	}
+
+	return nil
+}
+
+// encodedSize calls GetEncodedSize of the encoder of a SlimTrie, and converts
+// a panic caused by malformed bytes to an error.
+func encodedSize(st *SlimTrie, bs []byte) (n int, err error) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = malformedf("encoder: %v", r)
+		}
+	}()
+
+	return st.encoder.GetEncodedSize(bs), nil
+}
+
+func malformedf(format string, args ...interface{}) error {
+	return errors.Wrapf(ErrMalformed, format, args...)
+}
+
+func equalI32s(a, b []int32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func onesCount(words []uint64) int32 {
+	n := 0
+	for _, w := range words {
+		n += bits.OnesCount64(w)
+	}
+	return int32(n)
+}
+
+// bitCap returns the number of bits words can hold.
+func bitCap(words []uint64) int32 {
+	return int32(len(words)) << 6
+}
+
+// highestBit returns the position of the highest "1", or -1 if there is none.
+func highestBit(words []uint64) int32 {
+	for i := len(words) - 1; i >= 0; i-- {
+		if words[i] != 0 {
+			return int32(i<<6 + 63 - bits.LeadingZeros64(words[i]))
+		}
+	}
+	return -1
+}
+
+// lowestBit returns the position of the lowest "1", or -1 if there is none.
+func lowestBit(words []uint64) int32 {
+	for i, w := range words {
+		if w != 0 {
+			return int32(i<<6 + bits.TrailingZeros64(w))
+		}
+	}
+	return -1
+}
@@ -0,0 +1,181 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/openacid/errors"
+	"github.com/openacid/slim/encode"
+	"github.com/openacid/testkeys"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlimTrie_Validate(t *testing.T) {
+
+	ta := require.New(t)
+
+	opts := []Opt{
+		{},
+		{InnerPrefix: Bool(true)},
+		{LeafPrefix: Bool(true)},
+		{Complete: Bool(true)},
+		{FingerprintBits: 13},
+	}
+
+	for _, typ := range testkeys.AssetNames() {
+
+		keys := getKeys(typ)
+		if len(keys) >= 1000 {
+			continue
+		}
+		values := makeI32s(len(keys))
+
+		for _, opt := range opts {
+
+			st, err := NewSlimTrie(encode.I32{}, keys, values, opt)
+			ta.NoError(err)
+			ta.NoError(st.Validate(), "keys: %s, opt: %+v", typ, opt)
+
+			st, err = NewSlimTrie(nil, keys, nil, opt)
+			ta.NoError(err)
+			ta.NoError(st.Validate(), "filter mode, keys: %s, opt: %+v", typ, opt)
+
+			st, err = NewSlimTrie(encode.String16{}, keys, keys, opt)
+			ta.NoError(err)
+			ta.NoError(st.Validate(), "var-len values, keys: %s, opt: %+v", typ, opt)
+		}
+	}
+
+	st, err := NewSlimTrie(encode.I32{}, nil, nil)
+	ta.NoError(err)
+	ta.NoError(st.Validate())
+}
+
+func TestSlimTrie_Validate_malformed(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+
+	cases := []struct {
+		name    string
+		opt     Opt
+		corrupt func(ns *Nodes)
+	}{
+		{"NodeTypeBM truncated", Opt{}, func(ns *Nodes) {
+			ns.NodeTypeBM.Words = ns.NodeTypeBM.Words[:len(ns.NodeTypeBM.Words)/2]
+		}},
+		{"NodeTypeBM bit flipped", Opt{}, func(ns *Nodes) {
+			ns.NodeTypeBM.Words[3] ^= 1 << 7
+		}},
+		{"Inners truncated", Opt{}, func(ns *Nodes) {
+			ns.Inners.Words = ns.Inners.Words[:len(ns.Inners.Words)-1]
+			ns.Inners.indexit("r128")
+		}},
+		{"Inners RankIndex", Opt{}, func(ns *Nodes) {
+			ns.Inners.RankIndex[1]++
+		}},
+		{"ShortSize", Opt{}, func(ns *Nodes) {
+			ns.ShortSize++
+		}},
+		{"ShortTable", Opt{}, func(ns *Nodes) {
+			ns.ShortTable = ns.ShortTable[:len(ns.ShortTable)-1]
+		}},
+		{"BigInnerCnt", Opt{}, func(ns *Nodes) {
+			ns.BigInnerCnt++
+		}},
+		{"InnerPrefixes EltCnt", Opt{}, func(ns *Nodes) {
+			ns.InnerPrefixes.EltCnt++
+		}},
+		{"InnerPrefixes steps", Opt{}, func(ns *Nodes) {
+			ns.InnerPrefixes.Bytes = ns.InnerPrefixes.Bytes[1:]
+		}},
+		{"InnerPrefixes SelectIndex", Opt{InnerPrefix: Bool(true)}, func(ns *Nodes) {
+			ns.InnerPrefixes.PositionBM.SelectIndex[1]--
+		}},
+		{"InnerPrefixes content", Opt{InnerPrefix: Bool(true)}, func(ns *Nodes) {
+			ns.InnerPrefixes.Bytes = ns.InnerPrefixes.Bytes[:len(ns.InnerPrefixes.Bytes)-1]
+		}},
+		{"LeafPrefixes", Opt{LeafPrefix: Bool(true)}, func(ns *Nodes) {
+			ns.LeafPrefixes.PresenceBM.Words = ns.LeafPrefixes.PresenceBM.Words[:1]
+			ns.LeafPrefixes.PresenceBM.indexit("r64")
+		}},
+		{"Leaves truncated", Opt{}, func(ns *Nodes) {
+			ns.Leaves.Bytes = ns.Leaves.Bytes[:len(ns.Leaves.Bytes)-4]
+		}},
+		{"Leaves FixedSize", Opt{}, func(ns *Nodes) {
+			ns.Leaves.FixedSize = 2
+		}},
+		{"Fingerprints", Opt{FingerprintBits: 8}, func(ns *Nodes) {
+			ns.Fingerprints.Words = ns.Fingerprints.Words[:10]
+		}},
+		{"FingerprintBits", Opt{FingerprintBits: 8}, func(ns *Nodes) {
+			ns.FingerprintBits = 65
+		}},
+	}
+
+	for i, c := range cases {
+
+		st, err := NewSlimTrie(encode.I32{}, keys, values, c.opt)
+		ta.NoError(err)
+
+		c.corrupt(st.nodes)
+
+		err = st.Validate()
+		ta.Equal(ErrMalformed, errors.Cause(err), "%d-th: %s", i+1, c.name)
+	}
+
+	// var-len values with a malformed length.
+
+	st, err := NewSlimTrie(encode.String16{}, keys, keys)
+	ta.NoError(err)
+
+	st.nodes.Leaves.Bytes[0] = 0xff
+	ta.Equal(ErrMalformed, errors.Cause(st.Validate()))
+}
+
+func TestSlimTrie_Validate_onLoad(t *testing.T) {
+
+	ta := require.New(t)
+
+	keys := getKeys("20kvl10")
+	values := makeI32s(len(keys))
+
+	st1, err := NewSlimTrie(encode.I32{}, keys, values)
+	ta.NoError(err)
+
+	st1.nodes.ShortSize++
+
+	buf, err := st1.Marshal()
+	ta.NoError(err)
+
+	aligned, err := st1.MarshalAligned()
+	ta.NoError(err)
+
+	// not validated by default
+
+	st2, err := NewSlimTrie(encode.I32{}, nil, nil)
+	ta.NoError(err)
+	ta.NoError(st2.Unmarshal(buf))
+
+	st2, err = NewSlimTrie(encode.I32{}, nil, nil, Opt{ValidateOnLoad: Bool(true)})
+	ta.NoError(err)
+
+	err = st2.Unmarshal(buf)
+	ta.Equal(ErrMalformed, errors.Cause(err))
+	ta.Equal(int32(-1), st2.GetID(keys[0]))
+
+	err = st2.UnmarshalNoCopy(aligned)
+	ta.Equal(ErrMalformed, errors.Cause(err))
+	ta.Equal(int32(-1), st2.GetID(keys[0]))
+
+	// valid data
+
+	st1.nodes.ShortSize--
+
+	buf, err = st1.Marshal()
+	ta.NoError(err)
+
+	ta.NoError(st2.Unmarshal(buf))
+	testPresentKeysGet(t, st2, keys, values)
+}